@@ -0,0 +1,76 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func aliasRouter(t *testing.T, aliases map[string]string, responses ...*core.RawResponse) *Router {
+	t.Helper()
+	cfg := &config.LLMConfig{
+		Models: map[string]config.ModelConfig{
+			"gemini15flash":     {Provider: "test", Model: "g-flash", WebVariant: "gemini15flash-web"},
+			"gemini15flash-web": {Provider: "test", Model: "g-flash-web", SupportsWebSearch: true},
+		},
+		Aliases: aliases,
+	}
+	r, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clients[clientCacheKey(config.ModelConfig{Provider: "test"})] = &fakeClient{responses: responses}
+	return r
+}
+
+func TestAliasResolvesToTarget(t *testing.T) {
+	r := aliasRouter(t, map[string]string{"fast": "gemini15flash"}, textResponse("ok"))
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Model: "fast", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "gemini15flash" {
+		t.Errorf("resolved to %q", meta.ModelKey)
+	}
+}
+
+func TestAliasFollowsWebVariant(t *testing.T) {
+	r := aliasRouter(t, map[string]string{"fast": "gemini15flash"}, textResponse("ok"))
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Model:           "fast",
+		EnableWebSearch: true,
+		Messages:        []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "gemini15flash-web" {
+		t.Errorf("alias should behave exactly like the target, including web-variant resolution; got %q", meta.ModelKey)
+	}
+}
+
+func TestAliasChainsAndErrors(t *testing.T) {
+	// Chained aliases resolve through.
+	r := aliasRouter(t, map[string]string{"fast": "quick", "quick": "gemini15flash"}, textResponse("ok"))
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "fast", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Errorf("chained alias: %v", err)
+	}
+
+	// Dangling alias names both the alias and the missing target.
+	r = aliasRouter(t, map[string]string{"fast": "missing"})
+	_, err := r.ExecuteRaw(context.Background(), Request{Model: "fast", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if !errors.Is(err, ErrUnknownModel) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("dangling alias err = %v", err)
+	}
+
+	// Cycles are detected rather than looping forever.
+	r = aliasRouter(t, map[string]string{"a": "b", "b": "a"})
+	_, err = r.ExecuteRaw(context.Background(), Request{Model: "a", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("cycle err = %v", err)
+	}
+}
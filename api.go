@@ -0,0 +1,323 @@
+// Package llmrouter routes chat, tool-calling, and structured-output
+// requests across multiple LLM providers behind one API. Models are declared
+// in config.yaml and addressed by key; the router picks a capable model when
+// none is named, runs the tool loop, and parses typed output.
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// Role identifies the author of a Message.
+type Role = core.Role
+
+const (
+	RoleSystem    = core.RoleSystem
+	RoleUser      = core.RoleUser
+	RoleAssistant = core.RoleAssistant
+	// RoleTool marks messages carrying tool results back to the model.
+	RoleTool = core.RoleTool
+)
+
+// Message is one entry in a conversation. Besides Role, Content, and
+// Images, callers may populate ToolCalls and ToolResults directly — for
+// example to resume a persisted conversation mid-tool-loop; the router maps
+// them to provider payloads exactly as it does for messages its own tool
+// loop produced.
+type Message = core.Message
+
+// ImageData is an in-memory image attachment; see Message.ImageData.
+type ImageData = core.ImageData
+
+// FileAttachment is a document attachment; see Message.Files.
+type FileAttachment = core.FileAttachment
+
+// AudioData is an audio input attachment; see Message.Audio.
+type AudioData = core.AudioData
+
+// ToolCall is a single tool invocation requested by the model.
+type ToolCall = core.ToolCall
+
+// ToolResult carries a tool's output back to the model.
+type ToolResult = core.ToolResult
+
+// Usage reports token consumption.
+type Usage = core.Usage
+
+// FinishReason reports why the model stopped generating.
+type FinishReason = core.FinishReason
+
+// Timing is the latency breakdown of one provider call.
+type Timing = core.Timing
+
+const (
+	FinishStop          = core.FinishStop
+	FinishLength        = core.FinishLength
+	FinishToolCalls     = core.FinishToolCalls
+	FinishContentFilter = core.FinishContentFilter
+)
+
+// Tool is implemented by anything the model may call during a request.
+//
+// Parameters returns a pointer to the struct the model's arguments are
+// decoded into; its fields (with json and description tags) define the
+// tool's schema. Execute receives a freshly decoded instance of that struct.
+type Tool interface {
+	Name() string
+	Description() string
+	Parameters() any
+	Execute(ctx context.Context, args any) (any, error)
+}
+
+// ToolChoice constrains the model's tool usage; see the ToolChoice*
+// constructors.
+type ToolChoice = core.ToolChoice
+
+var (
+	// ToolChoiceAuto lets the model decide freely (the default).
+	ToolChoiceAuto = ToolChoice{Mode: "auto"}
+	// ToolChoiceNone forbids tool calls while still advertising the
+	// definitions.
+	ToolChoiceNone = ToolChoice{Mode: "none"}
+	// ToolChoiceRequired forces the model to call at least one tool.
+	ToolChoiceRequired = ToolChoice{Mode: "required"}
+)
+
+// ToolChoiceTool forces the model to call the named tool.
+func ToolChoiceTool(name string) ToolChoice {
+	return ToolChoice{Mode: "tool", Name: name}
+}
+
+// Decision is an approval callback's verdict on a pending tool call.
+type Decision int
+
+const (
+	// Approve lets the call proceed.
+	Approve Decision = iota
+	// Deny skips the call and feeds a refusal back to the model.
+	Deny
+	// Abort fails the whole request with ErrToolCallAborted.
+	Abort
+)
+
+// ToolErrorMode selects what happens when a tool's Execute returns an
+// error; see Request.ToolErrorMode.
+type ToolErrorMode int
+
+const (
+	// ToolErrorAbort fails the whole request on the first tool error — the
+	// historical behavior.
+	ToolErrorAbort ToolErrorMode = iota
+	// ToolErrorReport feeds the failure back to the model as a tool result
+	// so it can retry with different arguments or answer without the tool.
+	// After maxConsecutiveToolErrorTurns turns that each produced an error,
+	// the loop aborts with the underlying error wrapped.
+	ToolErrorReport
+)
+
+// maxConsecutiveToolErrorTurns caps how long ToolErrorReport keeps feeding
+// failures back before giving up.
+const maxConsecutiveToolErrorTurns = 3
+
+// ToolDefErrorPolicy selects how tool-definition reflection failures are
+// handled; see Request.ToolDefPolicy.
+type ToolDefErrorPolicy int
+
+const (
+	// FailFast aborts the request when any tool's parameters cannot be
+	// reflected.
+	FailFast ToolDefErrorPolicy = iota
+	// SkipBrokenTools omits tools whose parameters cannot be reflected and
+	// proceeds with the rest.
+	SkipBrokenTools
+)
+
+// RawTool is optionally implemented by tools that want the untouched JSON
+// arguments and supply a hand-written JSON Schema instead of the
+// reflection-over-struct path — proxy tools, schemaless tools, and bridges
+// like MCP. When a Tool also implements RawTool, the router uses JSONSchema
+// verbatim in the tool definition and calls ExecuteRaw with the raw
+// arguments; Parameters and Execute are then ignored.
+type RawTool interface {
+	JSONSchema() string
+	ExecuteRaw(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// ToolResolver supplies tools dynamically; see Request.ToolResolver.
+type ToolResolver interface {
+	// Tools returns the definitions to advertise for this request.
+	Tools(ctx context.Context) ([]Tool, error)
+	// Lookup resolves one tool the model asked to call. It may return
+	// tools that were not advertised (large catalogs narrow the advertised
+	// set but still honor known names).
+	Lookup(ctx context.Context, name string) (Tool, error)
+}
+
+// Float64 returns a pointer to v, for Request's optional sampling fields.
+func Float64(v float64) *float64 { return &v }
+
+// Int returns a pointer to v, for Request's optional sampling fields.
+func Int(v int) *int { return &v }
+
+// Request describes one call through the router.
+type Request struct {
+	// Model is the config key of the model to use. When empty the router
+	// auto-selects the first configured model capable of serving the request.
+	Model string
+
+	// System is the system prompt, injected consistently for every
+	// provider: Gemini receives it as systemInstruction, OpenAI as a
+	// leading system message. It is merged with — not duplicated by — any
+	// explicit RoleSystem messages.
+	System string
+
+	Messages []Message
+
+	// Temperature, TopP, TopK, and MaxTokens are pointers so an explicit
+	// zero is distinguishable from unset: nil falls back to the model's
+	// configured defaults (then the provider's), while a set value —
+	// including 0 — always wins. The Float64/Int helpers build them
+	// inline. Some providers reject TopK combined with TopP, so setting
+	// both is logged.
+	Temperature *float64
+	TopP        *float64
+	TopK        *int
+	MaxTokens   *int
+
+	// CandidateCount requests several alternative completions per call
+	// (OpenAI n, Gemini candidateCount); retrieve them with ExecuteRawMulti
+	// or ExecuteMulti. The tool loop only ever follows the first candidate.
+	CandidateCount int
+
+	// Seed requests deterministic sampling on providers that support it
+	// (OpenAI seed, Gemini generationConfig.seed). It is a pointer so seed
+	// 0 is distinguishable from unset.
+	Seed *int64
+
+	// Timeout bounds the entire request, including all tool-loop turns.
+	Timeout time.Duration
+
+	// ToolTimeout bounds each individual Tool.Execute call. When both
+	// Timeout and ToolTimeout apply, the tool's context carries the tighter
+	// deadline; tools can inspect it with RemainingBudget.
+	ToolTimeout time.Duration
+
+	// Tools the model may call. Requires a model with supports_tools.
+	Tools []Tool
+
+	// ForceJSON requests the provider's schema-free JSON mode (Gemini
+	// responseMimeType, OpenAI json_object) even when no output schema is in
+	// play. It has no effect on plain-text requests unless explicitly set.
+	ForceJSON bool
+
+	// ToolResolver supplies tools dynamically per request (for per-user
+	// catalogs too large or too variable for a static slice). Mutually
+	// exclusive with Tools: it is consulted once for the advertised
+	// definitions and again to look up each tool the model calls.
+	ToolResolver ToolResolver
+
+	// StrictTools asks OpenAI models that support it for strict function
+	// calling: schemas are transformed to the required shape (every
+	// property required, optional fields made nullable,
+	// additionalProperties false) and enforced provider-side. Models
+	// without supports_json_schema fall back to regular function calling.
+	StrictTools bool
+
+	// ToolChoice controls whether and which tools the model may call while
+	// still advertising the definitions: ToolChoiceAuto (default),
+	// ToolChoiceNone, ToolChoiceRequired, or ToolChoiceTool("name").
+	ToolChoice ToolChoice
+
+	// ApprovalFunc, when set, is consulted before executing any tool that
+	// requires approval (see Decision). Tools may opt out of the prompt by
+	// implementing RequiresApproval() bool and returning false.
+	ApprovalFunc func(ctx context.Context, info ToolCallInfo) (Decision, error)
+
+	// ToolErrorMode controls whether a failing tool aborts the request
+	// (default) or is reported back to the model; see ToolErrorMode.
+	ToolErrorMode ToolErrorMode
+
+	// ToolDefPolicy controls what happens when reflecting a tool's
+	// parameters fails while building definitions. The default, FailFast,
+	// aborts the request; SkipBrokenTools omits the offending tool (logged
+	// and reported in ResponseMeta.SkippedTools) so the rest can proceed.
+	ToolDefPolicy ToolDefErrorPolicy
+
+	// StrictToolArgs enables stricter validation of model-supplied tool
+	// arguments: explicit nulls for required non-nullable parameters are
+	// violations, and unknown (misspelled) fields are flagged with a
+	// corrective message listing the valid field names instead of
+	// vanishing silently.
+	StrictToolArgs bool
+
+	// ProviderOptions is the escape hatch for provider knobs this package
+	// doesn't model (OpenAI logit_bias, Gemini responseModalities, ...),
+	// keyed by provider name. The sub-map matching the resolved model's
+	// provider is deep-merged into the outgoing payload; keys colliding
+	// with router-managed fields are ignored with a loud log.
+	ProviderOptions map[string]map[string]any
+
+	// SchemaRetries re-asks the model up to N times when typed parsing (or
+	// ValidateOutput validation) fails, appending the invalid output and a
+	// corrective prompt. Retries never re-run tools, and their usage is
+	// counted in the aggregated metadata. Default 0: fail on the first
+	// invalid response.
+	SchemaRetries int
+
+	// ValidateOutput checks the typed response against the generated
+	// schema after unmarshalling — required fields present, enums
+	// respected, no unknown fields under StrictToolArgs... strictness — and
+	// fails with ErrOutputValidation listing the violations when it
+	// doesn't conform. Go zero values otherwise absorb missing fields
+	// silently.
+	ValidateOutput bool
+
+	// FailIfOverContext makes the router estimate the prompt size up front
+	// and fail with ErrContextWindowExceeded when it (plus MaxTokens) cannot
+	// fit the model's configured context window, instead of letting the
+	// provider reject the call.
+	FailIfOverContext bool
+
+	// RequireTags restricts routing to models carrying every listed
+	// capability tag from config. It applies both to auto-selection and as
+	// validation of an explicitly chosen model.
+	RequireTags []string
+
+	// EnableWebSearch routes to the model's web variant when configured.
+	EnableWebSearch bool
+
+	// FallbackModels names model keys to try, in order, when the resolved
+	// model fails with a transient error after exhausting its retries. It
+	// overrides the model's configured fallbacks list.
+	FallbackModels []string
+
+	// RequestID correlates this request across logs, metadata, and
+	// provider-side records; the router generates one when empty.
+	RequestID string
+
+	// Tags is caller-attached request metadata (tenant, feature, trace
+	// ID) echoed back in usage reports; the router never interprets it.
+	Tags map[string]string
+
+	// CaptureTranscript asks for the full executed conversation (tool
+	// calls and results included) in ResponseMeta.Transcript, for audit and
+	// for persisting/resuming agent runs.
+	CaptureTranscript bool
+
+	// OnEvent, when set, receives progress events synchronously as the tool
+	// loop runs. See ToolLoopEvent.
+	OnEvent func(ToolLoopEvent)
+
+	// requireStructured is set internally by the typed entry points so
+	// model selection can account for structured-output support.
+	requireStructured bool
+
+	// logger carries the per-execution logger (request ID attached); set
+	// by executeInternal.
+	logger *slog.Logger
+}
@@ -0,0 +1,91 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// readOnlyTool opts out of approval prompts.
+type readOnlyTool struct{ echoTool }
+
+func (readOnlyTool) RequiresApproval() bool { return false }
+
+func TestApprovalDecisions(t *testing.T) {
+	run := func(decision Decision, responses []*core.RawResponse) (string, *echoTool, error) {
+		fake := &fakeClient{responses: responses}
+		r := newTestRouter(t, testModels(), fake)
+		tool := &echoTool{}
+		req := userRequest("go")
+		req.Tools = []Tool{tool}
+		req.ApprovalFunc = func(ctx context.Context, info ToolCallInfo) (Decision, error) {
+			if info.Name != "echo" {
+				t.Errorf("info = %+v", info)
+			}
+			return decision, nil
+		}
+		out, err := r.ExecuteRaw(context.Background(), req)
+		return out, tool, err
+	}
+
+	out, tool, err := run(Approve, []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`), textResponse("done"),
+	})
+	if err != nil || out != "done" || tool.executed != 1 {
+		t.Errorf("approve: out=%q executed=%d err=%v", out, tool.executed, err)
+	}
+
+	out, tool, err = run(Deny, []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`), textResponse("ok without tool"),
+	})
+	if err != nil || tool.executed != 0 {
+		t.Errorf("deny: out=%q executed=%d err=%v", out, tool.executed, err)
+	}
+
+	_, tool, err = run(Abort, []*core.RawResponse{toolCallResponse("echo", `{"text":"x"}`)})
+	if !errors.Is(err, ErrToolCallAborted) || tool.executed != 0 {
+		t.Errorf("abort: executed=%d err=%v", tool.executed, err)
+	}
+}
+
+func TestReadOnlyToolSkipsApproval(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`), textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	tool := &readOnlyTool{}
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	prompted := false
+	req.ApprovalFunc = func(ctx context.Context, info ToolCallInfo) (Decision, error) {
+		prompted = true
+		return Deny, nil
+	}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if prompted {
+		t.Error("read-only tool should skip the approval prompt")
+	}
+	if tool.executed != 1 {
+		t.Errorf("executed = %d", tool.executed)
+	}
+}
+
+func TestApprovalErrorAborts(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{toolCallResponse("echo", `{"text":"x"}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.ApprovalFunc = func(ctx context.Context, info ToolCallInfo) (Decision, error) {
+		return Approve, fmt.Errorf("approval service down")
+	}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "approval service down") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package llmrouter
+
+import (
+	"context"
+	"time"
+)
+
+// RemainingBudget reports how much time is left before the deadline the
+// router established for the current tool execution. Tools can use it to
+// pick a cheaper code path when time is short. The context handed to
+// Tool.Execute always carries the tightest applicable deadline (per-tool
+// timeout, request timeout, or the caller's own deadline), so this simply
+// reflects that. It returns false when no deadline applies.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
@@ -0,0 +1,83 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// budgetProbeTool records the remaining budget it observed when executed.
+type budgetProbeTool struct {
+	remaining time.Duration
+	hasBudget bool
+}
+
+func (b *budgetProbeTool) Name() string        { return "echo" }
+func (b *budgetProbeTool) Description() string { return "Echoes the provided text" }
+func (b *budgetProbeTool) Parameters() any     { return &echoArgs{} }
+func (b *budgetProbeTool) Execute(ctx context.Context, args any) (any, error) {
+	b.remaining, b.hasBudget = RemainingBudget(ctx)
+	return "ok", nil
+}
+
+func runBudgetProbe(t *testing.T, req Request) *budgetProbeTool {
+	t.Helper()
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "hi"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	probe := &budgetProbeTool{}
+	req.Tools = []Tool{probe}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatalf("ExecuteRaw: %v", err)
+	}
+	return probe
+}
+
+func TestRemainingBudgetNoDeadline(t *testing.T) {
+	probe := runBudgetProbe(t, userRequest("hi"))
+	if probe.hasBudget {
+		t.Errorf("expected no budget without timeouts, got %v", probe.remaining)
+	}
+}
+
+func TestRemainingBudgetTotalTimeout(t *testing.T) {
+	req := userRequest("hi")
+	req.Timeout = time.Minute
+	probe := runBudgetProbe(t, req)
+	if !probe.hasBudget || probe.remaining <= 0 || probe.remaining > time.Minute {
+		t.Errorf("remaining = %v (%v), want within (0, 1m]", probe.remaining, probe.hasBudget)
+	}
+}
+
+func TestRemainingBudgetToolTimeout(t *testing.T) {
+	req := userRequest("hi")
+	req.ToolTimeout = 10 * time.Second
+	probe := runBudgetProbe(t, req)
+	if !probe.hasBudget || probe.remaining > 10*time.Second {
+		t.Errorf("remaining = %v (%v), want within (0, 10s]", probe.remaining, probe.hasBudget)
+	}
+}
+
+func TestRemainingBudgetTightestWins(t *testing.T) {
+	req := userRequest("hi")
+	req.Timeout = time.Minute
+	req.ToolTimeout = 5 * time.Second
+	probe := runBudgetProbe(t, req)
+	if !probe.hasBudget || probe.remaining > 5*time.Second {
+		t.Errorf("remaining = %v, want tool timeout (5s) to win over total", probe.remaining)
+	}
+
+	// And the other way around: a nearly exhausted total budget must win
+	// over a generous per-tool timeout.
+	req = userRequest("hi")
+	req.Timeout = 2 * time.Second
+	req.ToolTimeout = time.Minute
+	probe = runBudgetProbe(t, req)
+	if !probe.hasBudget || probe.remaining > 2*time.Second {
+		t.Errorf("remaining = %v, want total timeout (2s) to win over per-tool", probe.remaining)
+	}
+}
@@ -0,0 +1,83 @@
+package llmrouter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// gateClient blocks each call until released, recording peak concurrency.
+type gateClient struct {
+	mu      sync.Mutex
+	active  int32
+	peak    int32
+	release chan struct{}
+}
+
+func (g *gateClient) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	cur := atomic.AddInt32(&g.active, 1)
+	g.mu.Lock()
+	if cur > g.peak {
+		g.peak = cur
+	}
+	g.mu.Unlock()
+	<-g.release
+	atomic.AddInt32(&g.active, -1)
+	return textResponse("ok"), nil
+}
+
+func TestWithMaxConcurrentCalls(t *testing.T) {
+	gate := &gateClient{release: make(chan struct{})}
+	r := newTestRouter(t, testModels(), gate, WithMaxConcurrentCalls(2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.ExecuteRaw(context.Background(), userRequest("hi"))
+		}()
+	}
+
+	// Let the goroutines saturate the semaphore.
+	deadline := time.Now().Add(time.Second)
+	for r.Stats().InFlightCalls < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := r.Stats().InFlightCalls; got != 2 {
+		t.Errorf("InFlightCalls = %d, want 2 at saturation", got)
+	}
+	close(gate.release)
+	wg.Wait()
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	if gate.peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", gate.peak)
+	}
+	if r.Stats().InFlightCalls != 0 {
+		t.Errorf("InFlightCalls = %d after drain, want 0", r.Stats().InFlightCalls)
+	}
+}
+
+func TestConcurrencyLimiterRespectsContext(t *testing.T) {
+	gate := &gateClient{release: make(chan struct{})}
+	defer close(gate.release)
+	r := newTestRouter(t, testModels(), gate, WithMaxConcurrentCalls(1))
+
+	go func() { _, _ = r.ExecuteRaw(context.Background(), userRequest("hold the slot")) }()
+	deadline := time.Now().Add(time.Second)
+	for r.Stats().InFlightCalls < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.ExecuteRaw(ctx, userRequest("blocked")); err == nil {
+		t.Error("blocked caller should fail when its context expires")
+	}
+}
@@ -0,0 +1,95 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ConsensusResult is one model's contribution to ExecuteConsensus.
+type ConsensusResult[T any] struct {
+	Model string
+	Value T
+	Err   error
+}
+
+// ConsensusFunc resolves disagreement between models: it receives every
+// result (including failures) and picks or merges a final value.
+type ConsensusFunc[T any] func(results []ConsensusResult[T]) (T, error)
+
+// ExecuteConsensus fans the same request out to several configured models
+// concurrently and parses each answer into T. When a strict majority of all
+// requested models (failures count against the quorum, never toward it)
+// produced deeply equal values, that value is returned directly; otherwise
+// the caller's judge decides. Usage from every call is aggregated into the
+// returned metadata, whose ModelKey names the first model that agreed with
+// the winning value (empty when the judge merged).
+func ExecuteConsensus[T any](ctx context.Context, r *Router, req Request, models []string, judge ConsensusFunc[T]) (T, ResponseMeta, error) {
+	var zero T
+	if len(models) == 0 {
+		return zero, ResponseMeta{}, fmt.Errorf("llmrouter: consensus requires at least one model")
+	}
+
+	results := make([]ConsensusResult[T], len(models))
+	metas := make([]ResponseMeta, len(models))
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func(i int, model string) {
+			defer wg.Done()
+			mReq := req
+			mReq.Model = model
+			v, meta, err := executeTypedMeta[T](ctx, r, mReq)
+			results[i] = ConsensusResult[T]{Model: model, Value: v, Err: err}
+			metas[i] = meta
+		}(i, m)
+	}
+	wg.Wait()
+
+	meta := ResponseMeta{}
+	for _, m := range metas {
+		meta.Usage.Add(m.Usage)
+		meta.Cost += m.Cost
+	}
+
+	// Quorum: a strict majority of all requested models, so failures make
+	// agreement harder rather than shrinking the electorate.
+	quorum := len(models)/2 + 1
+	for i, a := range results {
+		if a.Err != nil {
+			continue
+		}
+		agree := 0
+		for _, b := range results {
+			if b.Err == nil && reflect.DeepEqual(a.Value, b.Value) {
+				agree++
+			}
+		}
+		if agree >= quorum {
+			meta.ModelKey = results[i].Model
+			return a.Value, meta, nil
+		}
+	}
+
+	if judge == nil {
+		var errs []error
+		for _, res := range results {
+			if res.Err != nil {
+				errs = append(errs, res.Err)
+			}
+		}
+		return zero, meta, fmt.Errorf("llmrouter: no consensus among %d models and no judge supplied (errors: %v)", len(models), errs)
+	}
+	v, err := judge(results)
+	return v, meta, err
+}
+
+// executeTypedMeta adapts executeTyped to always return usable metadata.
+func executeTypedMeta[T any](ctx context.Context, r *Router, req Request) (T, ResponseMeta, error) {
+	v, res, err := executeTyped[T](ctx, r, req)
+	if res == nil {
+		return v, ResponseMeta{}, err
+	}
+	return v, res.meta, err
+}
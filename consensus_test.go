@@ -0,0 +1,117 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+type extraction struct {
+	Name string `json:"name"`
+}
+
+func consensusModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"m1": {Provider: "test", Model: "w1", SupportsStructuredOutput: true},
+		"m2": {Provider: "test", Model: "w2", SupportsStructuredOutput: true},
+		"m3": {Provider: "test", Model: "w3", SupportsStructuredOutput: true},
+	}
+}
+
+func TestConsensusQuorum(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"w1": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w2": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w3": {responses: []*core.RawResponse{textResponse(`{"name":"Grace"}`)}},
+	}}
+	r := newTestRouter(t, consensusModels(), mm)
+
+	v, meta, err := ExecuteConsensus[extraction](context.Background(), r,
+		Request{Messages: []Message{{Role: RoleUser, Content: "extract"}}},
+		[]string{"m1", "m2", "m3"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "Ada" {
+		t.Errorf("value = %+v, want quorum winner", v)
+	}
+	if meta.Usage.TotalTokens != 45 { // three calls of 15 each
+		t.Errorf("usage = %+v, want aggregation across all calls", meta.Usage)
+	}
+}
+
+func TestConsensusJudgeInvokedOnDisagreement(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"w1": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w2": {responses: []*core.RawResponse{textResponse(`{"name":"Grace"}`)}},
+	}}
+	r := newTestRouter(t, consensusModels(), mm)
+
+	judged := false
+	v, _, err := ExecuteConsensus(context.Background(), r,
+		Request{Messages: []Message{{Role: RoleUser, Content: "extract"}}},
+		[]string{"m1", "m2"},
+		func(results []ConsensusResult[extraction]) (extraction, error) {
+			judged = true
+			if len(results) != 2 {
+				t.Errorf("judge saw %d results", len(results))
+			}
+			return results[1].Value, nil
+		})
+	if err != nil || !judged || v.Name != "Grace" {
+		t.Errorf("v=%+v judged=%v err=%v", v, judged, err)
+	}
+}
+
+func TestConsensusNoJudgeNoQuorum(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"w1": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w2": {errs: []error{errors.New("boom")}},
+	}}
+	r := newTestRouter(t, consensusModels(), mm)
+	// With one success out of two, quorum (2) is unreachable.
+	_, _, err := ExecuteConsensus[extraction](context.Background(), r,
+		Request{Messages: []Message{{Role: RoleUser, Content: "extract"}}},
+		[]string{"m1", "m2"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "no consensus") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// TestConsensusQuorumCountsAllRequestedModels pins the quorum semantics:
+// failures count against the majority threshold, never toward it.
+func TestConsensusQuorumCountsAllRequestedModels(t *testing.T) {
+	// Two agreeing successes out of three requested models reach the
+	// strict majority despite one failure.
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"w1": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w2": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w3": {errs: []error{errors.New("boom")}},
+	}}
+	r := newTestRouter(t, consensusModels(), mm)
+	v, _, err := ExecuteConsensus[extraction](context.Background(), r,
+		Request{Messages: []Message{{Role: RoleUser, Content: "extract"}}},
+		[]string{"m1", "m2", "m3"}, nil)
+	if err != nil || v.Name != "Ada" {
+		t.Errorf("v=%+v err=%v", v, err)
+	}
+
+	// A single success out of three — 100%% of the successes — is not a
+	// majority of the requested models and must not win on its own.
+	mm = &multiModelClient{perModel: map[string]*fakeClient{
+		"w1": {responses: []*core.RawResponse{textResponse(`{"name":"Ada"}`)}},
+		"w2": {errs: []error{errors.New("boom")}},
+		"w3": {errs: []error{errors.New("boom")}},
+	}}
+	r = newTestRouter(t, consensusModels(), mm)
+	_, _, err = ExecuteConsensus[extraction](context.Background(), r,
+		Request{Messages: []Message{{Role: RoleUser, Content: "extract"}}},
+		[]string{"m1", "m2", "m3"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "no consensus") {
+		t.Errorf("err = %v, want no-consensus failure", err)
+	}
+}
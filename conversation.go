@@ -0,0 +1,73 @@
+package llmrouter
+
+import (
+	"context"
+	"sync"
+)
+
+// Conversation carries multi-turn state so applications don't have to
+// rebuild the message slice on every call. Each Ask appends the user turn,
+// runs the request, and folds the executed transcript — including any
+// tool-call and tool-result messages the loop produced — back into the
+// conversation for the next turn.
+type Conversation struct {
+	r    *Router
+	base Request
+
+	mu   sync.Mutex
+	msgs []Message
+}
+
+// NewConversation starts a conversation. base acts as the template for every
+// turn: its Model, sampling parameters, and Tools are reused, and its
+// Messages (typically a leading system prompt) seed the history.
+func (r *Router) NewConversation(base Request) *Conversation {
+	seed := make([]Message, len(base.Messages))
+	copy(seed, base.Messages)
+	base.Messages = nil
+	return &Conversation{r: r, base: base, msgs: seed}
+}
+
+// Ask appends prompt as a user message, runs the request, records the
+// executed transcript, and returns the assistant's answer.
+func (c *Conversation) Ask(ctx context.Context, prompt string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, err := c.r.executeInternal(ctx, c.nextRequest(prompt), "")
+	if err != nil {
+		return "", err
+	}
+	c.msgs = res.transcript
+	return res.content, nil
+}
+
+// AskTyped is the structured-output variant of Conversation.Ask, parsing the
+// answer into T the same way Execute does.
+func AskTyped[T any](ctx context.Context, c *Conversation, prompt string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, res, err := executeTyped[T](ctx, c.r, c.nextRequest(prompt))
+	if err != nil {
+		return out, err
+	}
+	c.msgs = res.transcript
+	return out, nil
+}
+
+// Messages returns a copy of the conversation so far, including tool calls
+// and tool results, suitable for persistence.
+func (c *Conversation) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Message, len(c.msgs))
+	copy(out, c.msgs)
+	return out
+}
+
+// nextRequest builds the request for one more user turn. Callers must hold
+// c.mu.
+func (c *Conversation) nextRequest(prompt string) Request {
+	req := c.base
+	req.Messages = append(append([]Message{}, c.msgs...), Message{Role: RoleUser, Content: prompt})
+	return req
+}
@@ -0,0 +1,95 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestConversationCarriesToolHistory(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "hi"}`),
+		textResponse("first answer"),
+		textResponse("second answer"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	conv := r.NewConversation(Request{
+		Model:    "fake",
+		Messages: []Message{{Role: RoleSystem, Content: "be terse"}},
+		Tools:    []Tool{&echoTool{}},
+	})
+
+	out, err := conv.Ask(context.Background(), "say hi")
+	if err != nil {
+		t.Fatalf("Ask 1: %v", err)
+	}
+	if out != "first answer" {
+		t.Errorf("answer 1 = %q", out)
+	}
+
+	if _, err := conv.Ask(context.Background(), "again"); err != nil {
+		t.Fatalf("Ask 2: %v", err)
+	}
+
+	// The third provider call must have seen the full history: system, user,
+	// assistant tool call, tool result, assistant answer, user.
+	last := fake.calls[len(fake.calls)-1].Messages
+	var sawToolCall, sawToolResult, sawAnswer bool
+	for _, m := range last {
+		if len(m.ToolCalls) > 0 {
+			sawToolCall = true
+		}
+		if len(m.ToolResults) > 0 {
+			sawToolResult = true
+		}
+		if m.Role == core.RoleAssistant && m.Content == "first answer" {
+			sawAnswer = true
+		}
+	}
+	if !sawToolCall || !sawToolResult || !sawAnswer {
+		t.Errorf("second turn missing history (toolCall=%v toolResult=%v answer=%v):\n%+v",
+			sawToolCall, sawToolResult, sawAnswer, last)
+	}
+	if last[0].Role != core.RoleSystem || last[0].Content != "be terse" {
+		t.Errorf("system prompt not preserved: %+v", last[0])
+	}
+	if got := last[len(last)-1]; got.Role != core.RoleUser || got.Content != "again" {
+		t.Errorf("latest user turn = %+v", got)
+	}
+
+	msgs := conv.Messages()
+	if len(msgs) != len(last)+1 { // plus the final "second answer"
+		t.Errorf("Messages() = %d entries, want %d", len(msgs), len(last)+1)
+	}
+	// Mutating the copy must not affect the conversation.
+	msgs[0].Content = "changed"
+	if conv.Messages()[0].Content != "be terse" {
+		t.Error("Messages() returned a live reference")
+	}
+}
+
+func TestConversationAskTyped(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items": ["a", "b"]}`),
+		textResponse("plain follow-up"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	conv := r.NewConversation(Request{Model: "fake"})
+	list, err := AskTyped[itemList](context.Background(), conv, "list please")
+	if err != nil {
+		t.Fatalf("AskTyped: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("list = %+v", list)
+	}
+	if _, err := conv.Ask(context.Background(), "thanks"); err != nil {
+		t.Fatalf("Ask after AskTyped: %v", err)
+	}
+	second := fake.calls[1].Messages
+	if len(second) != 3 { // user, assistant JSON answer, user
+		t.Errorf("second call saw %d messages: %+v", len(second), second)
+	}
+}
@@ -0,0 +1,35 @@
+package llmrouter
+
+import (
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// callCost prices one provider call with the model's configured per-MTok
+// rates. Models without pricing cost zero.
+func callCost(cfg config.ModelConfig, usage core.Usage) float64 {
+	return float64(usage.PromptTokens)*cfg.InputCostPerMTok/1e6 +
+		float64(usage.CompletionTokens)*cfg.OutputCostPerMTok/1e6
+}
+
+// EstimateCost predicts the worst-case dollar cost of a request before
+// sending it: the estimated prompt tokens at the input rate plus
+// Request.MaxTokens (or the model's configured output cap) at the output
+// rate. Tool-loop turns cannot be predicted and are not included.
+func (r *Router) EstimateCost(req Request) (float64, error) {
+	_, cfg, err := r.selectModel(req)
+	if err != nil {
+		return 0, err
+	}
+	defs, _, err := r.buildToolDefs(req.Tools, req.ToolDefPolicy)
+	if err != nil {
+		return 0, err
+	}
+	prompt := r.estimateTokens(core.CallParams{System: req.System, Messages: req.Messages, ToolDefs: defs})
+	output := orZero(req.MaxTokens)
+	if output == 0 {
+		output = cfg.MaxOutputTokens
+	}
+	return float64(prompt)*cfg.InputCostPerMTok/1e6 +
+		float64(output)*cfg.OutputCostPerMTok/1e6, nil
+}
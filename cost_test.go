@@ -0,0 +1,72 @@
+package llmrouter
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func pricedModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"priced": {
+			Provider:          "test",
+			Model:             "m",
+			SupportsTools:     true,
+			InputCostPerMTok:  10, // $10 per MTok in
+			OutputCostPerMTok: 30, // $30 per MTok out
+			MaxOutputTokens:   1000,
+		},
+	}
+}
+
+func TestCostSummedAcrossToolLoop(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "a"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, pricedModels(), fake)
+	req := Request{Model: "priced", Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []Tool{&echoTool{}}}
+
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two turns of 10 prompt + 5 completion tokens each:
+	// 20*10/1e6 + 10*30/1e6 = 0.0002 + 0.0003
+	want := 0.0005
+	if math.Abs(meta.Cost-want) > 1e-12 {
+		t.Errorf("Cost = %v, want %v", meta.Cost, want)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	r := newTestRouter(t, pricedModels(), &fakeClient{})
+	req := Request{
+		Model:     "priced",
+		Messages:  []Message{{Role: RoleUser, Content: strings.Repeat("x", 400)}}, // ~100 tokens
+		MaxTokens: Int(500),
+	}
+	got, err := r.EstimateCost(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 100*10.0/1e6 + 500*30.0/1e6
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("EstimateCost = %v, want %v", got, want)
+	}
+
+	// Without MaxTokens the configured output cap is assumed.
+	req.MaxTokens = nil
+	got, err = r.EstimateCost(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = 100*10.0/1e6 + 1000*30.0/1e6
+	if math.Abs(got-want) > 1e-12 {
+		t.Errorf("EstimateCost (default cap) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package llmrouter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+var fakeNow = time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func deprecationRouter(t *testing.T, mc config.ModelConfig, responses int) (*Router, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	mc.Provider = "test"
+	var resps []*core.RawResponse
+	for i := 0; i < responses; i++ {
+		resps = append(resps, textResponse("ok"))
+	}
+	r := newTestRouter(t, map[string]config.ModelConfig{"old": mc}, &fakeClient{responses: resps}, WithLogger(logger))
+	r.now = func() time.Time { return fakeNow }
+	return r, &buf
+}
+
+func TestDeprecatedModelWarnsOnce(t *testing.T) {
+	r, buf := deprecationRouter(t, config.ModelConfig{Model: "m", Deprecated: true}, 2)
+	req := Request{Model: "old", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if got := strings.Count(buf.String(), "model is deprecated"); got != 1 {
+		t.Errorf("deprecation warned %d times, want once:\n%s", got, buf.String())
+	}
+}
+
+func TestSunsetModelBlocked(t *testing.T) {
+	r, _ := deprecationRouter(t, config.ModelConfig{Model: "m", SunsetDate: "2025-06-01"}, 0)
+	_, err := r.ExecuteRaw(context.Background(), Request{Model: "old", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if !errors.Is(err, ErrModelSunset) {
+		t.Errorf("err = %v, want ErrModelSunset", err)
+	}
+}
+
+func TestSunsetModelAllowed(t *testing.T) {
+	r, buf := deprecationRouter(t, config.ModelConfig{Model: "m", SunsetDate: "2025-06-01", AllowSunset: true}, 1)
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "old", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("allow_sunset model should still work: %v", err)
+	}
+	if !strings.Contains(buf.String(), "past sunset") {
+		t.Errorf("expected a warning for allowed sunset model:\n%s", buf.String())
+	}
+}
+
+func TestSunsetModelNotAutoSelected(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-sunset": {Provider: "test", Model: "a", SunsetDate: "2025-06-01"},
+		"bbb-live":   {Provider: "test", Model: "b"},
+	}
+	r := newTestRouter(t, models, &fakeClient{responses: []*core.RawResponse{textResponse("ok")}})
+	r.now = func() time.Time { return fakeNow }
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "bbb-live" {
+		t.Errorf("auto-selected %q, want sunset model skipped", meta.ModelKey)
+	}
+}
+
+func TestSunsetWarningsFromConfig(t *testing.T) {
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gone":    {SunsetDate: "2025-06-01"},
+		"soon":    {SunsetDate: "2025-07-01"},
+		"faraway": {SunsetDate: "2026-06-01"},
+		"dep":     {Deprecated: true},
+	}}
+	warnings := cfg.SunsetWarnings(fakeNow)
+	joined := strings.Join(warnings, "\n")
+	for _, want := range []string{`"gone" passed`, `"soon" sunsets`, `"dep" is deprecated`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("warnings missing %q:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "faraway") {
+		t.Errorf("distant sunset should not warn:\n%s", joined)
+	}
+}
+
+func TestFilesRequireCapableModel(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"nofiles": {Provider: "test", Model: "m"},
+	}
+	r := newTestRouter(t, models, &fakeClient{})
+	req := Request{Model: "nofiles", Messages: []Message{{
+		Role:  RoleUser,
+		Files: []FileAttachment{{MIME: "application/pdf", Data: []byte("%PDF-")}},
+	}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); !errors.Is(err, ErrNoCapableModel) {
+		t.Errorf("err = %v, want ErrNoCapableModel for files on non-supporting model", err)
+	}
+}
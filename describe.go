@@ -0,0 +1,45 @@
+package llmrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// DescribeTools renders the tools' definitions — names, descriptions, and
+// reflected parameters with their types, requiredness, descriptions, and
+// examples — as human-readable text. It shows exactly what the model will
+// see, so generated documentation stays in sync with the schemas.
+func DescribeTools(tools []Tool) (string, error) {
+	var b strings.Builder
+	for i, t := range tools {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s: %s\n", t.Name(), t.Description())
+		params, err := util.GenerateToolParameters(t.Parameters())
+		if err != nil {
+			return "", fmt.Errorf("tool %s: %w", t.Name(), err)
+		}
+		for _, p := range params {
+			typ, _ := p.Schema["type"].(string)
+			line := fmt.Sprintf("  %s (%s", p.Name, typ)
+			if p.Required {
+				line += ", required"
+			}
+			line += ")"
+			if p.Description != "" {
+				line += ": " + p.Description
+			}
+			if ex, ok := p.Schema["examples"].([]any); ok && len(ex) > 0 {
+				if js, err := json.Marshal(ex[0]); err == nil {
+					line += fmt.Sprintf(" (e.g. %s)", js)
+				}
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String(), nil
+}
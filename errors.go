@@ -0,0 +1,76 @@
+package llmrouter
+
+import (
+	"errors"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+var (
+	// ErrUnknownModel is returned when Request.Model names a key that is not
+	// in the configuration.
+	ErrUnknownModel = errors.New("llmrouter: unknown model")
+
+	// ErrNoCapableModel is returned when auto-selection finds no configured
+	// model that satisfies the request's requirements.
+	ErrNoCapableModel = errors.New("llmrouter: no configured model satisfies the request")
+
+	// ErrUnknownProvider is returned when a model's provider field does not
+	// name a supported provider.
+	ErrUnknownProvider = errors.New("llmrouter: unknown provider")
+
+	// ErrUnknownTool is returned when the model requests a tool that was not
+	// supplied on the request.
+	ErrUnknownTool = errors.New("llmrouter: model requested unknown tool")
+
+	// ErrStructuredOutput is returned when the model's output cannot be
+	// parsed into the requested type, even after repair.
+	ErrStructuredOutput = errors.New("llmrouter: model output did not match the requested schema")
+
+	// ErrToolLoopLimit is returned when the model keeps requesting tools
+	// past the configured maximum number of turns.
+	ErrToolLoopLimit = errors.New("llmrouter: tool loop exceeded maximum turns")
+
+	// ErrTruncated is returned when output could not be used because
+	// generation was cut off by the token limit.
+	ErrTruncated = errors.New("llmrouter: output truncated by token limit")
+
+	// ErrModelSunset is returned when a model is selected past its
+	// configured sunset date without allow_sunset.
+	ErrModelSunset = errors.New("llmrouter: model past sunset date")
+
+	// ErrContextWindowExceeded is returned when a prompt cannot fit the
+	// selected model's context window.
+	ErrContextWindowExceeded = errors.New("llmrouter: prompt exceeds model context window")
+
+	// ErrToolCallAborted is returned when an approval callback aborts the
+	// request over a pending tool call.
+	ErrToolCallAborted = errors.New("llmrouter: tool call aborted by approval callback")
+
+	// ErrInvalidToolArgs is returned (or reported to the model, under
+	// ToolErrorReport) when model-supplied arguments fail schema
+	// validation.
+	ErrInvalidToolArgs = errors.New("llmrouter: tool arguments failed validation")
+
+	// ErrInvalidTool is returned before any provider call when a supplied
+	// tool has an unusable name or description.
+	ErrInvalidTool = errors.New("llmrouter: invalid tool")
+
+	// ErrAllToolsDenied is returned when the resolved model's tool
+	// allow/deny lists filter out every tool the request supplied.
+	ErrAllToolsDenied = errors.New("llmrouter: model's tool policy denies every supplied tool")
+
+	// ErrOutputValidation is returned when Request.ValidateOutput finds the
+	// model's response violating the generated schema even though it
+	// unmarshalled cleanly.
+	ErrOutputValidation = errors.New("llmrouter: output does not conform to the schema")
+)
+
+// Typed provider failure classes, surfaced by Ping and provider calls.
+var (
+	// ErrAuthFailed marks provider rejections of the configured API key.
+	ErrAuthFailed = core.ErrAuthFailed
+	// ErrModelNotFound marks provider responses saying the underlying
+	// model name does not exist.
+	ErrModelNotFound = core.ErrModelNotFound
+)
@@ -0,0 +1,59 @@
+package llmrouter
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ToolLoopEventType identifies a stage of the tool loop.
+type ToolLoopEventType string
+
+const (
+	// EventModelTurnStarted fires before each provider call.
+	EventModelTurnStarted ToolLoopEventType = "model_turn_started"
+	// EventToolCallRequested fires for each tool invocation the model asked
+	// for, before the tool runs.
+	EventToolCallRequested ToolLoopEventType = "tool_call_requested"
+	// EventToolExecuted fires after a tool has run successfully.
+	EventToolExecuted ToolLoopEventType = "tool_executed"
+	// EventFinalAnswer fires when the model produces its final content.
+	EventFinalAnswer ToolLoopEventType = "final_answer"
+)
+
+// ToolLoopEvent is delivered to Request.OnEvent as the tool loop progresses.
+// Only the fields relevant to the event type are populated.
+type ToolLoopEvent struct {
+	Type ToolLoopEventType
+	// Turn is the zero-based model turn the event belongs to.
+	Turn int
+
+	// ToolName and Args are set on tool_call_requested and tool_executed.
+	ToolName string
+	Args     json.RawMessage
+
+	// Duration and ResultSize (serialized bytes) are set on tool_executed.
+	Duration   time.Duration
+	ResultSize int
+
+	// Content is set on final_answer.
+	Content string
+}
+
+// ToolCallInfo identifies one tool invocation for hooks and approval
+// callbacks.
+type ToolCallInfo struct {
+	CallID string
+	Name   string
+	Args   json.RawMessage
+	// Turn is the zero-based model turn that requested the call.
+	Turn int
+}
+
+// emit delivers ev to the request's OnEvent callback, if any. Callbacks are
+// invoked synchronously from the tool loop so callers can drive progress UIs;
+// a nil callback costs nothing.
+func (req *Request) emit(ev ToolLoopEvent) {
+	if req.OnEvent != nil {
+		req.OnEvent(ev)
+	}
+}
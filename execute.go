@@ -0,0 +1,255 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// ExecuteRaw runs the request and returns the model's final text content,
+// after any tool-loop turns have completed.
+func (r *Router) ExecuteRaw(ctx context.Context, req Request) (string, error) {
+	res, err := r.executeInternal(ctx, req, "")
+	if err != nil {
+		return "", err
+	}
+	return res.content, nil
+}
+
+// Execute runs the request and parses the model's output into T. A JSON
+// schema reflected from T is sent to models that support structured output;
+// for the rest the raw content is parsed with a repair pass.
+func Execute[T any](ctx context.Context, r *Router, req Request) (T, error) {
+	out, _, err := executeTyped[T](ctx, r, req)
+	return out, err
+}
+
+// executeTyped is the shared implementation behind Execute, ExecuteWithMeta,
+// and the typed conversation path. Non-object target types (slices, maps)
+// are wrapped in an {"items": ...} envelope for providers that require
+// object-rooted schemas, and unwrapped transparently when parsing.
+func executeTyped[T any](ctx context.Context, r *Router, req Request) (T, *execResult, error) {
+	var zero T
+
+	// String-ish targets want prose, not structured output: reflecting a
+	// schema from *string made Gemini return a JSON-quoted string with
+	// literal quotes in it. Run with raw semantics and hand the content
+	// back untouched.
+	if target := reflect.TypeOf((*T)(nil)).Elem(); target.Kind() == reflect.String || target == rawMessageType {
+		res, err := r.executeInternal(ctx, req, "")
+		if err != nil {
+			return zero, nil, err
+		}
+		out := zero
+		rv := reflect.ValueOf(&out).Elem()
+		if target.Kind() == reflect.String {
+			rv.SetString(res.content)
+		} else {
+			rv.SetBytes([]byte(res.content))
+		}
+		return out, res, nil
+	}
+
+	schema, err := util.CachedResponseSchema(zero)
+	if err != nil {
+		return zero, nil, fmt.Errorf("llmrouter: generating schema: %w", err)
+	}
+	wrapped := rootNeedsWrapping[T]()
+	sentSchema := schema
+	if wrapped {
+		if sentSchema, err = wrapSchemaInItems(schema); err != nil {
+			return zero, nil, fmt.Errorf("llmrouter: wrapping schema: %w", err)
+		}
+	}
+
+	req.requireStructured = true
+	res, err := r.executeInternal(ctx, req, sentSchema)
+	if err != nil {
+		return zero, nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		out, content, perr := parseTyped[T](res.content, wrapped)
+		// Second-pass validation: providers occasionally return extra
+		// fields or wrong-typed values even under a responseSchema. The
+		// pass is fatal under ValidateOutput; with only SchemaRetries set
+		// it still drives the re-ask loop, accepting the final attempt.
+		var verr error
+		if perr == nil && (req.ValidateOutput || req.SchemaRetries > 0) {
+			verr = validateOutputContent(content, schema, req.StrictToolArgs)
+		}
+		if perr == nil && verr == nil {
+			return out, res, nil
+		}
+		if attempt >= req.SchemaRetries {
+			if verr != nil {
+				if !req.ValidateOutput {
+					r.execLogger(req).Warn("typed output still violates schema after retries", "error", verr)
+					return out, res, nil
+				}
+				return zero, res, verr
+			}
+			if res.meta.Truncated {
+				return zero, res, fmt.Errorf("%w: raise Request.MaxTokens (output stopped with finish reason %q)", ErrTruncated, res.meta.FinishReason)
+			}
+			return zero, res, fmt.Errorf("%w: %s%s", ErrStructuredOutput, describeUnmarshalError(perr), schemaViolationDetail(res.content, schema))
+		}
+
+		reason := verr
+		if perr != nil {
+			reason = perr
+		}
+		r.execLogger(req).Warn("typed output invalid, re-asking", "attempt", attempt+1, "error", reason)
+		retryReq := req
+		// The transcript already contains executed tool results; retries
+		// only correct the final answer and must not re-run tools.
+		retryReq.Tools = nil
+		retryReq.ToolResolver = nil
+		retryReq.Messages = append(append([]Message{}, res.transcript...), Message{
+			Role: RoleUser,
+			Content: fmt.Sprintf(
+				"Your previous response was not valid JSON for this schema: %v. Respond ONLY with corrected JSON.", reason),
+		})
+		retryRes, rerr := r.executeInternal(ctx, retryReq, sentSchema)
+		if rerr != nil {
+			return zero, res, rerr
+		}
+		mergeRetryMeta(&retryRes.meta, res.meta)
+		res = retryRes
+	}
+}
+
+// mergeRetryMeta folds the previous attempt's accounting into the retry's
+// metadata so aggregated usage covers every attempt.
+func mergeRetryMeta(cur *ResponseMeta, prev ResponseMeta) {
+	usage := prev.Usage
+	usage.Add(cur.Usage)
+	cur.Usage = usage
+	cur.Cost += prev.Cost
+	cur.ToolTurns += prev.ToolTurns
+	cur.TurnUsage = append(append([]Usage{}, prev.TurnUsage...), cur.TurnUsage...)
+	cur.TurnLatencies = append(append([]time.Duration{}, prev.TurnLatencies...), cur.TurnLatencies...)
+	cur.TurnTimings = append(append([]Timing{}, prev.TurnTimings...), cur.TurnTimings...)
+}
+
+// parseTyped unmarshals content into T with the repair fallback, accepting
+// the {"items": ...} envelope for wrapped root types. It returns the
+// content that actually parsed (unwrapped form) for validation.
+func parseTyped[T any](raw string, wrapped bool) (T, string, error) {
+	var out T
+	try := func(content string) (T, string, bool) {
+		var v T
+		if json.Unmarshal([]byte(content), &v) == nil {
+			return v, content, true
+		}
+		if wrapped {
+			var envelope struct {
+				Items json.RawMessage `json:"items"`
+			}
+			if json.Unmarshal([]byte(content), &envelope) == nil && len(envelope.Items) > 0 {
+				if json.Unmarshal(envelope.Items, &v) == nil {
+					return v, string(envelope.Items), true
+				}
+			}
+		}
+		return v, "", false
+	}
+	if v, content, ok := try(raw); ok {
+		return v, content, nil
+	}
+	repaired := util.RepairJSON(raw)
+	if v, content, ok := try(repaired); ok {
+		return v, content, nil
+	}
+	err := json.Unmarshal([]byte(repaired), &out)
+	return out, "", err
+}
+
+// rootNeedsWrapping reports whether T's JSON root is not an object and so
+// needs the items envelope for providers that insist on object roots.
+func rootNeedsWrapping[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// wrapSchemaInItems wraps a non-object root schema in
+// {"items": <schema>} so the provider sees an object root.
+func wrapSchemaInItems(schema string) (string, error) {
+	var inner map[string]any
+	if err := json.Unmarshal([]byte(schema), &inner); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"items": inner},
+		"required":   []string{"items"},
+	})
+	return string(b), err
+}
+
+// schemaViolationDetail explains a parse failure in schema terms when the
+// content is valid JSON that just doesn't match, so callers see why rather
+// than a bare sentinel.
+func schemaViolationDetail(content, schema string) string {
+	repaired := util.RepairJSON(content)
+	if !json.Valid([]byte(repaired)) {
+		return ""
+	}
+	var schemaMap map[string]any
+	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
+		return ""
+	}
+	vs := util.ValidateAgainstSchema([]byte(repaired), schemaMap, false)
+	if len(vs) == 0 {
+		return ""
+	}
+	return "; schema violations:\n" + util.FormatViolations(vs)
+}
+
+// validateOutputContent checks parsed output against the sanitized schema,
+// rendering violations through the shared formatter.
+func validateOutputContent(content, schema string, strict bool) error {
+	var schemaMap map[string]any
+	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
+		return nil
+	}
+	if vs := util.ValidateAgainstSchema([]byte(content), schemaMap, strict); len(vs) > 0 {
+		return fmt.Errorf("%w:\n%s", ErrOutputValidation, util.FormatViolations(vs))
+	}
+	return nil
+}
+
+// describeUnmarshalError renders unmarshal failures through the shared
+// validation formatter so structured-output errors and tool-argument errors
+// read the same way.
+func describeUnmarshalError(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := typeErr.Field
+		if path == "" {
+			path = "(root)"
+		}
+		return util.FormatViolations([]util.Violation{{
+			Path:     path,
+			Code:     "wrong_type",
+			Expected: typeErr.Type.String(),
+			Actual:   typeErr.Value,
+		}})
+	}
+	return err.Error()
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
@@ -0,0 +1,97 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/retry"
+)
+
+// TestFailoverResumesMidToolLoop drives a workflow where the primary model
+// completes a tool turn and then dies; the failover model must see the
+// accumulated conversation (with neutral call IDs) rather than starting
+// over.
+func TestFailoverResumesMidToolLoop(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"alpha": {Provider: "test", Model: "m-alpha", SupportsTools: true},
+		"beta":  {Provider: "test", Model: "m-beta", SupportsTools: true},
+	}
+	alpha := &fakeClient{
+		responses: []*core.RawResponse{{
+			ToolCalls: []core.ToolCall{{CallID: "prov_abc123", Name: "echo", Args: []byte(`{"text":"hi"}`)}},
+			Usage:     core.Usage{TotalTokens: 10},
+		}},
+		errs: []error{nil, retry.Transient(errors.New("outage mid-loop"))},
+	}
+	beta := &fakeClient{responses: []*core.RawResponse{textResponse("finished by beta")}}
+	mm := &multiModelClient{perModel: map[string]*fakeClient{"m-alpha": alpha, "m-beta": beta}}
+	r := newTestRouter(t, models, mm, WithFailover(true))
+
+	tool := &echoTool{}
+	out, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Model:    "alpha",
+		Messages: []Message{{Role: RoleUser, Content: "do the thing"}},
+		Tools:    []Tool{tool},
+	})
+	if err != nil {
+		t.Fatalf("failover should finish the workflow: %v", err)
+	}
+	if out != "finished by beta" || meta.ModelKey != "beta" {
+		t.Errorf("out=%q model=%q", out, meta.ModelKey)
+	}
+	if tool.executed != 1 {
+		t.Errorf("tool ran %d times, want exactly once (no restart)", tool.executed)
+	}
+
+	// Beta must have received the accumulated conversation with neutralized
+	// call IDs.
+	replay := beta.calls[0].Messages
+	var sawCall, sawResult bool
+	for _, m := range replay {
+		for _, tc := range m.ToolCalls {
+			sawCall = true
+			if tc.CallID == "prov_abc123" {
+				t.Errorf("provider call ID leaked into replay: %q", tc.CallID)
+			}
+			if tc.CallID == "" {
+				t.Error("replayed call has no ID")
+			}
+		}
+		for _, tr := range m.ToolResults {
+			sawResult = true
+			if tr.CallID == "prov_abc123" {
+				t.Errorf("provider call ID leaked into replayed result: %q", tr.CallID)
+			}
+		}
+	}
+	if !sawCall || !sawResult {
+		t.Errorf("replay missing tool history: %+v", replay)
+	}
+}
+
+func TestNeutralizeCallIDsPreservesPairing(t *testing.T) {
+	msgs := []core.Message{
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{
+			{CallID: "x1", Name: "a"}, {CallID: "x2", Name: "b"},
+		}},
+		{Role: core.RoleTool, ToolResults: []core.ToolResult{
+			{CallID: "x2", Name: "b"}, {CallID: "x1", Name: "a"},
+		}},
+	}
+	out := neutralizeCallIDs(msgs)
+	calls := out[0].ToolCalls
+	results := out[1].ToolResults
+	if calls[0].CallID == "x1" || calls[1].CallID == "x2" {
+		t.Errorf("IDs not neutralized: %+v", calls)
+	}
+	if results[0].CallID != calls[1].CallID || results[1].CallID != calls[0].CallID {
+		t.Errorf("pairing broken: calls=%+v results=%+v", calls, results)
+	}
+	// Original slice untouched.
+	if msgs[0].ToolCalls[0].CallID != "x1" {
+		t.Error("neutralizeCallIDs mutated its input")
+	}
+}
@@ -0,0 +1,93 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/retry"
+)
+
+// multiModelClient routes fake calls by model name so one test client can
+// serve several configured models.
+type multiModelClient struct {
+	perModel map[string]*fakeClient
+}
+
+func (m *multiModelClient) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	fc, ok := m.perModel[p.Model]
+	if !ok {
+		return nil, fmt.Errorf("no fake for model %q", p.Model)
+	}
+	return fc.Call(ctx, p)
+}
+
+func fallbackModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"primary":  {Provider: "test", Model: "m-primary", Fallbacks: []string{"backup"}},
+		"backup":   {Provider: "test", Model: "m-backup"},
+		"toolless": {Provider: "test", Model: "m-toolless"},
+	}
+}
+
+func TestFallbackOnTransientError(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-primary": {errs: []error{retry.Transient(errors.New("503 from provider"))}},
+		"m-backup":  {responses: []*core.RawResponse{textResponse("saved by backup")}},
+	}}
+	r := newTestRouter(t, fallbackModels(), mm)
+
+	out, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Model:    "primary",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("expected fallback to answer: %v", err)
+	}
+	if out != "saved by backup" || meta.ModelKey != "backup" {
+		t.Errorf("out=%q meta.ModelKey=%q, want backup to answer", out, meta.ModelKey)
+	}
+}
+
+func TestNoFallbackOnNonTransientError(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-primary": {errs: []error{errors.New("400 bad request")}},
+		"m-backup":  {responses: []*core.RawResponse{textResponse("should not run")}},
+	}}
+	r := newTestRouter(t, fallbackModels(), mm)
+
+	_, err := r.ExecuteRaw(context.Background(), Request{
+		Model:    "primary",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err == nil {
+		t.Fatal("non-transient error must not fall back")
+	}
+	if len(mm.perModel["m-backup"].calls) != 0 {
+		t.Error("backup model was called for a non-transient failure")
+	}
+}
+
+func TestRequestFallbacksOverrideConfig(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-primary":  {errs: []error{retry.Transient(errors.New("boom"))}},
+		"m-toolless": {responses: []*core.RawResponse{textResponse("from request fallback")}},
+		"m-backup":   {},
+	}}
+	r := newTestRouter(t, fallbackModels(), mm)
+
+	out, err := r.ExecuteRaw(context.Background(), Request{
+		Model:          "primary",
+		FallbackModels: []string{"toolless"},
+		Messages:       []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil || out != "from request fallback" {
+		t.Errorf("out=%q err=%v", out, err)
+	}
+	if len(mm.perModel["m-backup"].calls) != 0 {
+		t.Error("config fallback used despite request override")
+	}
+}
@@ -0,0 +1,615 @@
+// Package config loads the router's model configuration from config.yaml.
+//
+// The file has a single top-level llm key:
+//
+//	llm:
+//	  models:
+//	    gpt4o:
+//	      provider: openai
+//	      model: gpt-4o
+//	      api_key: ${OPENAI_API_KEY}
+//	      supports_tools: true
+//
+// String values support ${VAR} environment interpolation, and individual
+// fields can be overridden with LLM__-prefixed environment variables, e.g.
+// LLM__MODELS__gpt4o__api_key.
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration that unmarshals from YAML strings like
+// "30s" or "2m".
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// GenerationDefaults are per-model sampling defaults; nil fields leave the
+// provider default in place.
+type GenerationDefaults struct {
+	Temperature *float64 `yaml:"temperature"`
+	TopP        *float64 `yaml:"top_p"`
+	TopK        *int     `yaml:"top_k"`
+	MaxTokens   *int     `yaml:"max_tokens"`
+}
+
+// RetryConfig is a per-model override of the provider retry policy.
+type RetryConfig struct {
+	MaxAttempts int      `yaml:"max_attempts"`
+	BaseDelay   Duration `yaml:"base_delay"`
+	MaxDelay    Duration `yaml:"max_delay"`
+	JitterRatio float64  `yaml:"jitter_ratio"`
+}
+
+// validate rejects combinations the retry loop can't honor.
+func (rc *RetryConfig) validate() error {
+	if rc.MaxAttempts < 1 {
+		return fmt.Errorf("retry.max_attempts must be at least 1, got %d", rc.MaxAttempts)
+	}
+	if rc.MaxDelay > 0 && rc.BaseDelay > rc.MaxDelay {
+		return fmt.Errorf("retry.max_delay (%v) must not be below retry.base_delay (%v)",
+			time.Duration(rc.MaxDelay), time.Duration(rc.BaseDelay))
+	}
+	if rc.JitterRatio < 0 || rc.JitterRatio > 1 {
+		return fmt.Errorf("retry.jitter_ratio must be within [0, 1], got %v", rc.JitterRatio)
+	}
+	return nil
+}
+
+// ModelConfig describes one configured model.
+type ModelConfig struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"api_key"`
+	// APIKeys lists several keys to rotate round-robin (separate rate
+	// limits); when set it takes precedence over APIKey.
+	APIKeys []string `yaml:"api_keys"`
+	// BaseURL overrides the provider's default endpoint, for proxies and
+	// compatible self-hosted deployments.
+	BaseURL string `yaml:"base_url"`
+
+	SupportsTools            bool `yaml:"supports_tools"`
+	SupportsStructuredOutput bool `yaml:"supports_structured_output"`
+	// SupportsJSONSchema marks OpenAI models that accept response_format
+	// json_schema with strict enforcement; older models keep the
+	// json_object path.
+	SupportsJSONSchema bool `yaml:"supports_json_schema"`
+	SupportsWebSearch  bool `yaml:"supports_web_search"`
+	// SupportsFiles marks models that accept document attachments.
+	SupportsFiles bool `yaml:"supports_files"`
+	// SupportsAudio marks models that accept audio input.
+	SupportsAudio bool `yaml:"supports_audio"`
+	// Headers are extra HTTP headers applied to every outgoing request for
+	// this model — corporate gateways in front of the providers require
+	// them. Values support ${VAR} interpolation and are never logged.
+	Headers map[string]string `yaml:"headers"`
+
+	// AllowedTools, when non-empty, restricts which request tools may be
+	// offered to this model; DeniedTools blocks specific tools. Compliance
+	// rules live here so externally hosted models never see internal
+	// tools.
+	AllowedTools []string `yaml:"allowed_tools"`
+	DeniedTools  []string `yaml:"denied_tools"`
+
+	// Tags are free-form capability labels ("vision", "long-context",
+	// "pii-safe") requests can route on via Request.RequireTags.
+	Tags []string `yaml:"tags"`
+	// WebVariant names the model key to substitute when a request enables
+	// web search and this model doesn't support it natively.
+	WebVariant string `yaml:"web_variant"`
+
+	ContextWindow   int `yaml:"context_window"`
+	MaxOutputTokens int `yaml:"max_output_tokens"`
+
+	// Timeout bounds each individual provider call for this model, applied
+	// as a context deadline per call (not a shared client timeout).
+	// Request.Timeout still bounds the whole request including all
+	// tool-loop turns; whichever deadline is tighter wins.
+	Timeout Duration `yaml:"timeout"`
+
+	// Defaults supplies per-model generation defaults applied when the
+	// corresponding Request field is unset; an explicit request value
+	// always wins. Pointer fields so a default of zero is expressible.
+	Defaults *GenerationDefaults `yaml:"defaults"`
+
+	// Retry overrides the compiled-in retry policy (5 attempts, 200ms
+	// base, 3s cap) for this model's provider calls.
+	Retry *RetryConfig `yaml:"retry"`
+
+	// RPMLimit and TPMLimit cap requests and tokens per minute with a
+	// client-side token bucket, shared across goroutines, instead of
+	// relying on provider 429s. Zero disables the corresponding limit.
+	RPMLimit int `yaml:"rpm_limit"`
+	TPMLimit int `yaml:"tpm_limit"`
+
+	// InputCostPerMTok and OutputCostPerMTok are the model's prices in
+	// dollars per million tokens, used for cost logging and estimation;
+	// CachedInputCostPerMTok is the discounted rate for cached prompt
+	// tokens where the provider bills them separately. They live in config
+	// rather than hard-coded tables because prices change and private
+	// deployments have custom rates.
+	InputCostPerMTok       float64 `yaml:"input_cost_per_mtok"`
+	OutputCostPerMTok      float64 `yaml:"output_cost_per_mtok"`
+	CachedInputCostPerMTok float64 `yaml:"cached_input_cost_per_mtok"`
+
+	// Fallbacks names model keys to try, in order, when this model fails
+	// with a transient error after exhausting its retries.
+	Fallbacks []string `yaml:"fallbacks"`
+
+	// Deprecated marks a model whose selection should be warned about.
+	Deprecated bool `yaml:"deprecated"`
+	// SunsetDate (YYYY-MM-DD) is the date the provider retires the model.
+	// Selecting the model on or after this date fails unless AllowSunset is
+	// set.
+	SunsetDate string `yaml:"sunset_date"`
+	// AllowSunset permits selecting the model past its sunset date.
+	AllowSunset bool `yaml:"allow_sunset"`
+	// ReplacedBy names the model key requests should be transparently
+	// rerouted to; set it when the provider retires this model's ID.
+	ReplacedBy string `yaml:"replaced_by"`
+	// Split turns this entry into a virtual model that routes each request
+	// to one of the listed member keys by weight (percentages or any
+	// relative weights). Other fields on the entry are ignored.
+	Split map[string]int `yaml:"split"`
+}
+
+// Keys returns the model's API keys: APIKeys when configured, otherwise
+// the single APIKey.
+func (mc ModelConfig) Keys() []string {
+	if len(mc.APIKeys) > 0 {
+		return mc.APIKeys
+	}
+	return []string{mc.APIKey}
+}
+
+// HasTag reports whether the model carries the given capability tag.
+func (mc ModelConfig) HasTag(tag string) bool {
+	for _, t := range mc.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Sunset parses SunsetDate, reporting ok=false when unset or malformed.
+func (mc ModelConfig) Sunset() (time.Time, bool) {
+	if mc.SunsetDate == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", mc.SunsetDate)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// LLMConfig is the llm: section of the config file.
+type LLMConfig struct {
+	Models map[string]ModelConfig `yaml:"models"`
+	// Aliases maps stable logical names ("fast", "smart") onto model keys
+	// (or other aliases), so application code survives ops swapping the
+	// underlying model.
+	Aliases map[string]string `yaml:"aliases"`
+	// DefaultModel is used when a request names no model, instead of
+	// auto-selection. It must reference a configured model or alias.
+	DefaultModel string `yaml:"default_model"`
+}
+
+// knownProviders are the provider names the router can construct clients
+// for.
+var knownProviders = map[string]bool{"openai": true, "gemini": true}
+
+// Validate checks the configuration for mistakes that would otherwise only
+// surface at request time as ErrUnknownProvider or provider 401s. All
+// problems are reported at once, each naming the offending model key.
+func (c *LLMConfig) Validate() error {
+	var problems []string
+	keys := make([]string, 0, len(c.Models))
+	for k := range c.Models {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		mc := c.Models[key]
+		if len(mc.Split) > 0 {
+			// Virtual split entries carry no provider of their own.
+			for member := range mc.Split {
+				if _, ok := c.Models[member]; !ok {
+					problems = append(problems, fmt.Sprintf("model %q: split member %q does not exist", key, member))
+				}
+			}
+			continue
+		}
+		if !knownProviders[mc.Provider] {
+			problems = append(problems, fmt.Sprintf("model %q: unknown provider %q", key, mc.Provider))
+		}
+		if mc.Model == "" {
+			problems = append(problems, fmt.Sprintf("model %q: model name is empty", key))
+		}
+		if mc.APIKey == "" && len(mc.APIKeys) == 0 {
+			problems = append(problems, fmt.Sprintf("model %q: api_key is empty", key))
+		}
+		if mc.WebVariant != "" {
+			if _, ok := c.Models[mc.WebVariant]; !ok {
+				problems = append(problems, fmt.Sprintf("model %q: web_variant %q does not exist", key, mc.WebVariant))
+			}
+		}
+		if mc.InputCostPerMTok < 0 || mc.OutputCostPerMTok < 0 || mc.CachedInputCostPerMTok < 0 {
+			problems = append(problems, fmt.Sprintf("model %q: costs must be non-negative", key))
+		}
+		if mc.ContextWindow > 0 && mc.MaxOutputTokens > mc.ContextWindow {
+			problems = append(problems, fmt.Sprintf("model %q: max_output_tokens (%d) exceeds context_window (%d)",
+				key, mc.MaxOutputTokens, mc.ContextWindow))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// SunsetWarnings reports models that are deprecated or approaching (within
+// 30 days of) their sunset date as of now, for surfacing at load or deploy
+// time.
+func (c *LLMConfig) SunsetWarnings(now time.Time) []string {
+	var warnings []string
+	keys := make([]string, 0, len(c.Models))
+	for k := range c.Models {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mc := c.Models[k]
+		sunset, ok := mc.Sunset()
+		switch {
+		case ok && !now.Before(sunset):
+			warnings = append(warnings, fmt.Sprintf("model %q passed its sunset date %s", k, mc.SunsetDate))
+		case ok && sunset.Sub(now) <= 30*24*time.Hour:
+			warnings = append(warnings, fmt.Sprintf("model %q sunsets on %s", k, mc.SunsetDate))
+		case mc.Deprecated:
+			warnings = append(warnings, fmt.Sprintf("model %q is deprecated", k))
+		}
+	}
+	return warnings
+}
+
+type fileConfig struct {
+	LLM LLMConfig `yaml:"llm"`
+}
+
+const defaultConfigPath = "config.yaml"
+
+// Load reads and parses the configuration at path, returning a fresh config
+// on every call — no process-wide singleton, so multiple routers (and tests)
+// can load different files in one process.
+func Load(path string) (*LLMConfig, error) {
+	return loadFile(path)
+}
+
+// defaultCache is a thin per-path cache behind LoadDefault, so the common
+// one-router case doesn't re-read and re-validate the file on every
+// construction. Keyed by resolved path: changing LLM_CONFIG_PATH between
+// calls loads the new file.
+var defaultCache = struct {
+	mu      sync.Mutex
+	entries map[string]*LLMConfig
+}{entries: map[string]*LLMConfig{}}
+
+// DefaultPath resolves the configuration path: LLM_CONFIG_PATH when set,
+// ./config.yaml otherwise.
+func DefaultPath() string {
+	if p := os.Getenv("LLM_CONFIG_PATH"); p != "" {
+		return p
+	}
+	return defaultConfigPath
+}
+
+// LoadDefault loads the configuration at DefaultPath with per-path
+// caching. LLM_CONFIG_PATH may name several files separated by the OS path
+// list separator (colon on Unix); they are deep-merged in order with
+// LoadPaths.
+func LoadDefault() (*LLMConfig, error) {
+	path := DefaultPath()
+	defaultCache.mu.Lock()
+	defer defaultCache.mu.Unlock()
+	if cfg, ok := defaultCache.entries[path]; ok {
+		return cfg, nil
+	}
+	var cfg *LLMConfig
+	var err error
+	if paths := filepath.SplitList(path); len(paths) > 1 {
+		cfg, err = LoadPaths(paths...)
+	} else {
+		cfg, err = loadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defaultCache.entries[path] = cfg
+	return cfg, nil
+}
+
+// LoadPaths loads and deep-merges several config files in order: later
+// files win per key, model maps merge by model key rather than being
+// replaced wholesale, and scalar conflicts resolve last-wins. A base config
+// in the repo plus an environment overlay mounted at deploy time is the
+// intended shape.
+func LoadPaths(paths ...string) (*LLMConfig, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no config paths given")
+	}
+	merged := map[string]any{}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		var doc map[string]any
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+		mergeYAML(merged, doc)
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFromBytes(out)
+}
+
+// mergeYAML deep-merges src into dst: nested maps merge recursively,
+// everything else (scalars, lists) is replaced last-wins.
+func mergeYAML(dst, src map[string]any) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]any); ok {
+			if dstMap, ok := dst[k].(map[string]any); ok {
+				mergeYAML(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func loadFile(path string) (*LLMConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		// Serverless deployments may ship no file at all; if the
+		// environment alone defines models, use it.
+		if os.IsNotExist(err) {
+			if cfg, envErr := LoadFromEnv(); envErr == nil {
+				return cfg, nil
+			}
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	cfg, err := LoadFromBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadFromEnv builds the full model map from LLM__MODELS__* environment
+// variables alone, for deployments that ship no config file. It errors when
+// the environment defines no models.
+func LoadFromEnv() (*LLMConfig, error) {
+	cfg := &LLMConfig{Models: map[string]ModelConfig{}}
+	applyEnvOverrides(cfg)
+	if len(cfg.Models) == 0 {
+		return nil, fmt.Errorf("no LLM__MODELS__ environment variables found")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadFromBytes parses configuration from raw YAML — embedded via go:embed
+// or fetched from a secrets manager — applying the same env-var
+// interpolation and LLM__ overrides the file path applies, with no
+// process-wide caching.
+func LoadFromBytes(b []byte) (*LLMConfig, error) {
+	var fc fileConfig
+	if err := yaml.Unmarshal(b, &fc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	cfg := fc.LLM
+	if cfg.Models == nil {
+		cfg.Models = map[string]ModelConfig{}
+	}
+	if err := resolveEnv(&cfg); err != nil {
+		return nil, err
+	}
+	applyEnvOverrides(&cfg)
+	for key, mc := range cfg.Models {
+		if mc.Retry != nil {
+			if err := mc.Retry.validate(); err != nil {
+				return nil, fmt.Errorf("model %q: %w", key, err)
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.DefaultModel != "" {
+		if _, ok := cfg.Models[cfg.DefaultModel]; !ok {
+			if _, ok := cfg.Aliases[cfg.DefaultModel]; !ok {
+				return nil, fmt.Errorf("default_model %q is not a configured model or alias", cfg.DefaultModel)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+// LoadFromReader is LoadFromBytes over an io.Reader.
+func LoadFromReader(r io.Reader) (*LLMConfig, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	return LoadFromBytes(b)
+}
+
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// resolveEnvString expands ${VAR} and ${VAR:-default} references from the
+// environment. References to unset variables without a default are left in
+// place and reported, so a missing OPENAI_API_KEY fails at load time
+// instead of reaching the provider as a literal placeholder.
+func resolveEnvString(s string) (string, []string) {
+	var unresolved []string
+	out := envPattern.ReplaceAllStringFunc(s, func(m string) string {
+		groups := envPattern.FindStringSubmatch(m)
+		name, hasDefault, def := groups[1], groups[2] != "", groups[3]
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		if hasDefault {
+			return def
+		}
+		unresolved = append(unresolved, m)
+		return m
+	})
+	return out, unresolved
+}
+
+func resolveEnv(cfg *LLMConfig) error {
+	var problems []string
+	resolve := func(modelKey, field, value string) string {
+		out, unresolved := resolveEnvString(value)
+		for _, u := range unresolved {
+			problems = append(problems, fmt.Sprintf("model %q field %s: unresolved placeholder %s", modelKey, field, u))
+		}
+		return out
+	}
+	for key, mc := range cfg.Models {
+		mc.APIKey = resolve(key, "api_key", mc.APIKey)
+		for i, k := range mc.APIKeys {
+			mc.APIKeys[i] = resolve(key, "api_keys", k)
+		}
+		mc.BaseURL = resolve(key, "base_url", mc.BaseURL)
+		mc.Model = resolve(key, "model", mc.Model)
+		for name, value := range mc.Headers {
+			mc.Headers[name] = resolve(key, "headers."+name, value)
+		}
+		cfg.Models[key] = mc
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("unresolved environment placeholders:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// applyEnvOverrides overlays LLM__MODELS__<key>__<field> environment
+// variables onto the loaded config. The double underscore is the segment
+// delimiter; the model-key segment is matched case-insensitively against
+// existing config keys (with '-' and '_' treated as equivalent, since
+// environment variable names can't carry hyphens portably), so keys like
+// "gpt4o-Web" stay addressable. Unmatched keys create new lowercase
+// entries, which is how environment-only configuration defines models.
+func applyEnvOverrides(cfg *LLMConfig) {
+	normalized := map[string]string{}
+	for existing := range cfg.Models {
+		normalized[normalizeEnvKey(existing)] = existing
+	}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, "LLM__MODELS__") {
+			continue
+		}
+		rest := strings.TrimPrefix(name, "LLM__MODELS__")
+		keySegment, field, ok := strings.Cut(rest, "__")
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(keySegment)
+		if existing, ok := normalized[normalizeEnvKey(keySegment)]; ok {
+			key = existing
+		} else {
+			normalized[normalizeEnvKey(key)] = key
+		}
+		mc := cfg.Models[key]
+		setModelField(&mc, strings.ToLower(field), value)
+		cfg.Models[key] = mc
+	}
+}
+
+// normalizeEnvKey folds case and the hyphen/underscore distinction for
+// model-key matching.
+func normalizeEnvKey(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), "-", "_")
+}
+
+func setModelField(mc *ModelConfig, field, value string) {
+	switch field {
+	case "provider":
+		mc.Provider = value
+	case "model":
+		mc.Model = value
+	case "api_key":
+		mc.APIKey = value
+	case "base_url":
+		mc.BaseURL = value
+	case "web_variant":
+		mc.WebVariant = value
+	case "supports_tools":
+		mc.SupportsTools = value == "true"
+	case "supports_structured_output":
+		mc.SupportsStructuredOutput = value == "true"
+	case "supports_json_schema":
+		mc.SupportsJSONSchema = value == "true"
+	case "supports_web_search":
+		mc.SupportsWebSearch = value == "true"
+	case "supports_files":
+		mc.SupportsFiles = value == "true"
+	case "supports_audio":
+		mc.SupportsAudio = value == "true"
+	case "input_cost_per_mtok":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			mc.InputCostPerMTok = f
+		}
+	case "output_cost_per_mtok":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			mc.OutputCostPerMTok = f
+		}
+	case "cached_input_cost_per_mtok":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			mc.CachedInputCostPerMTok = f
+		}
+	case "context_window":
+		if n, err := strconv.Atoi(value); err == nil {
+			mc.ContextWindow = n
+		}
+	case "max_output_tokens":
+		if n, err := strconv.Atoi(value); err == nil {
+			mc.MaxOutputTokens = n
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaultModelValidated(t *testing.T) {
+	path := writeConfig(t, `
+llm:
+  default_model: nope
+  models:
+    real:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+`)
+	if _, err := loadFile(path); err == nil || !strings.Contains(err.Error(), "default_model") {
+		t.Errorf("err = %v, want default_model validation failure", err)
+	}
+
+	good := writeConfig(t, `
+llm:
+  default_model: real
+  models:
+    real:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+`)
+	cfg, err := loadFile(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultModel != "real" {
+		t.Errorf("DefaultModel = %q", cfg.DefaultModel)
+	}
+}
+
+func TestLoadFromBytesAndReader(t *testing.T) {
+	t.Setenv("EMBEDDED_KEY", "resolved-secret")
+	yaml := `
+llm:
+  models:
+    gpt:
+      provider: openai
+      model: gpt-4o
+      api_key: ${EMBEDDED_KEY}
+`
+	cfg, err := LoadFromBytes([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Models["gpt"].APIKey != "resolved-secret" {
+		t.Errorf("interpolation not applied: %q", cfg.Models["gpt"].APIKey)
+	}
+
+	cfg2, err := LoadFromReader(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg2.Models["gpt"].Model != "gpt-4o" {
+		t.Errorf("reader load = %+v", cfg2.Models["gpt"])
+	}
+
+	if _, err := LoadFromBytes([]byte("llm: [broken")); err == nil {
+		t.Error("invalid YAML should fail")
+	}
+}
+
+func TestTimeoutParsing(t *testing.T) {
+	cfg, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    slow:
+      provider: gemini
+      model: gemini-pro
+      api_key: k
+      timeout: 2m
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(cfg.Models["slow"].Timeout) != 2*time.Minute {
+		t.Errorf("timeout = %v", cfg.Models["slow"].Timeout)
+	}
+
+	if _, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    bad:
+      provider: gemini
+      model: g
+      api_key: k
+      timeout: soonish
+`)); err == nil {
+		t.Error("invalid duration should fail to load")
+	}
+}
+
+func TestRetryConfigValidation(t *testing.T) {
+	base := `
+llm:
+  models:
+    m:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+      retry:
+        %s
+`
+	good := fmt.Sprintf(base, "max_attempts: 1")
+	cfg, err := LoadFromBytes([]byte(good))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Models["m"].Retry.MaxAttempts != 1 {
+		t.Errorf("retry = %+v", cfg.Models["m"].Retry)
+	}
+
+	for _, bad := range []string{
+		"max_attempts: 0",
+		"max_attempts: 3\n        base_delay: 5s\n        max_delay: 1s",
+		"max_attempts: 3\n        jitter_ratio: 1.5",
+	} {
+		if _, err := LoadFromBytes([]byte(fmt.Sprintf(base, bad))); err == nil {
+			t.Errorf("config with %q should fail validation", bad)
+		}
+	}
+}
+
+func TestValidateReportsAllProblemsAtOnce(t *testing.T) {
+	_, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    typo:
+      provider: opena
+      model: gpt-4o
+      api_key: k
+    nokey:
+      provider: openai
+      model: gpt-4o
+    dangling:
+      provider: gemini
+      model: g
+      api_key: k
+      web_variant: does-not-exist
+    inverted:
+      provider: gemini
+      model: g
+      api_key: k
+      context_window: 100
+      max_output_tokens: 200
+`))
+	if err == nil {
+		t.Fatal("expected validation failure")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		`model "typo": unknown provider "opena"`,
+		`model "nokey": api_key is empty`,
+		`model "dangling": web_variant "does-not-exist" does not exist`,
+		`model "inverted": max_output_tokens (200) exceeds context_window (100)`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in:\n%s", want, msg)
+		}
+	}
+}
+
+func TestLoadReturnsFreshConfigPerPath(t *testing.T) {
+	pathA := writeConfig(t, `
+llm:
+  models:
+    a:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+`)
+	pathB := writeConfig(t, `
+llm:
+  models:
+    b:
+      provider: gemini
+      model: gemini-2.0-flash
+      api_key: k
+`)
+	cfgA, err := Load(pathA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgB, err := Load(pathB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfgA.Models["a"]; !ok {
+		t.Errorf("cfgA = %+v", cfgA.Models)
+	}
+	if _, ok := cfgB.Models["b"]; !ok {
+		t.Errorf("cfgB = %+v, second load must not be poisoned by the first", cfgB.Models)
+	}
+}
+
+func TestLoadDefaultRespectsEnvChanges(t *testing.T) {
+	pathA := writeConfig(t, `
+llm:
+  models:
+    a: {provider: openai, model: gpt-4o, api_key: k}
+`)
+	pathB := writeConfig(t, `
+llm:
+  models:
+    b: {provider: openai, model: gpt-4o, api_key: k}
+`)
+	t.Setenv("LLM_CONFIG_PATH", pathA)
+	cfgA, err := LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LLM_CONFIG_PATH", pathB)
+	cfgB, err := LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfgA.Models["a"]; !ok {
+		t.Error("first default load wrong")
+	}
+	if _, ok := cfgB.Models["b"]; !ok {
+		t.Error("changing LLM_CONFIG_PATH must load the new file")
+	}
+}
+
+func TestLoadFromEnvBuildsFullModels(t *testing.T) {
+	t.Setenv("LLM__MODELS__GPT4O__PROVIDER", "openai")
+	t.Setenv("LLM__MODELS__GPT4O__MODEL", "gpt-4o")
+	t.Setenv("LLM__MODELS__GPT4O__API_KEY", "env-key")
+	t.Setenv("LLM__MODELS__GPT4O__SUPPORTS_TOOLS", "true")
+	t.Setenv("LLM__MODELS__GPT4O__CONTEXT_WINDOW", "128000")
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc, ok := cfg.Models["gpt4o"] // env segments are lowercased
+	if !ok {
+		t.Fatalf("models = %+v", cfg.Models)
+	}
+	if mc.Provider != "openai" || mc.APIKey != "env-key" || !mc.SupportsTools || mc.ContextWindow != 128000 {
+		t.Errorf("mc = %+v", mc)
+	}
+}
+
+func TestLoadFromEnvErrorsWhenEmpty(t *testing.T) {
+	if _, err := LoadFromEnv(); err == nil {
+		t.Error("no env models should be an error")
+	}
+}
+
+func TestMissingFileFallsBackToEnv(t *testing.T) {
+	t.Setenv("LLM__MODELS__GPT__PROVIDER", "openai")
+	t.Setenv("LLM__MODELS__GPT__MODEL", "gpt-4o")
+	t.Setenv("LLM__MODELS__GPT__API_KEY", "k")
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("missing file with env models should load: %v", err)
+	}
+	if _, ok := cfg.Models["gpt"]; !ok {
+		t.Errorf("models = %+v", cfg.Models)
+	}
+}
+
+func TestPricingMetadata(t *testing.T) {
+	cfg, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    priced:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+      input_cost_per_mtok: 2.5
+      output_cost_per_mtok: 10
+      cached_input_cost_per_mtok: 1.25
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := cfg.Models["priced"]
+	if mc.InputCostPerMTok != 2.5 || mc.OutputCostPerMTok != 10 || mc.CachedInputCostPerMTok != 1.25 {
+		t.Errorf("pricing = %+v", mc)
+	}
+
+	if _, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    bad:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+      input_cost_per_mtok: -1
+`)); err == nil || !strings.Contains(err.Error(), "non-negative") {
+		t.Errorf("negative cost err = %v", err)
+	}
+}
+
+func TestEnvOverridesMatchModelKeysCaseInsensitively(t *testing.T) {
+	t.Setenv("LLM__MODELS__GPT4O_WEB__API_KEY", "overridden")
+	cfg, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    gpt4o-Web:
+      provider: openai
+      model: gpt-4o-search
+      api_key: original
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Models) != 1 {
+		t.Fatalf("override created a duplicate entry: %v", cfg.Models)
+	}
+	if cfg.Models["gpt4o-Web"].APIKey != "overridden" {
+		t.Errorf("api_key = %q, want env override applied to the cased key", cfg.Models["gpt4o-Web"].APIKey)
+	}
+	// The untouched fields survive.
+	if cfg.Models["gpt4o-Web"].Model != "gpt-4o-search" {
+		t.Errorf("model = %q", cfg.Models["gpt4o-Web"].Model)
+	}
+}
+
+func TestEnvOverridesNestedFieldMapping(t *testing.T) {
+	t.Setenv("LLM__MODELS__gpt4o__api_key", "lower-case-env")
+	cfg, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    gpt4o:
+      provider: openai
+      model: gpt-4o
+      api_key: original
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Models["gpt4o"].APIKey != "lower-case-env" {
+		t.Errorf("api_key = %q", cfg.Models["gpt4o"].APIKey)
+	}
+}
+
+func TestUnresolvedPlaceholdersFailLoad(t *testing.T) {
+	os.Unsetenv("DEFINITELY_NOT_SET_A")
+	os.Unsetenv("DEFINITELY_NOT_SET_B")
+	_, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    gpt:
+      provider: openai
+      model: gpt-4o
+      api_key: ${DEFINITELY_NOT_SET_A}
+      base_url: ${DEFINITELY_NOT_SET_B}
+`))
+	if err == nil {
+		t.Fatal("unresolved placeholders should fail loading")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		`model "gpt" field api_key: unresolved placeholder ${DEFINITELY_NOT_SET_A}`,
+		`model "gpt" field base_url: unresolved placeholder ${DEFINITELY_NOT_SET_B}`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in:\n%s", want, msg)
+		}
+	}
+}
+
+func TestPlaceholderDefaults(t *testing.T) {
+	os.Unsetenv("MAYBE_BASE_URL")
+	t.Setenv("REAL_KEY", "from-env")
+	cfg, err := LoadFromBytes([]byte(`
+llm:
+  models:
+    gpt:
+      provider: openai
+      model: gpt-4o
+      api_key: ${REAL_KEY:-fallback}
+      base_url: ${MAYBE_BASE_URL:-https://proxy.internal/v1}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := cfg.Models["gpt"]
+	if mc.APIKey != "from-env" {
+		t.Errorf("set variable should win over default: %q", mc.APIKey)
+	}
+	if mc.BaseURL != "https://proxy.internal/v1" {
+		t.Errorf("default not applied: %q", mc.BaseURL)
+	}
+}
+
+func TestLoadPathsDeepMerges(t *testing.T) {
+	base := writeConfig(t, `
+llm:
+  default_model: fast
+  models:
+    fast:
+      provider: gemini
+      model: gemini-2.0-flash
+      api_key: placeholder
+      supports_tools: true
+    smart:
+      provider: openai
+      model: gpt-4o
+      api_key: placeholder
+`)
+	overlay := writeConfig(t, `
+llm:
+  models:
+    fast:
+      api_key: real-gemini-key
+    smart:
+      api_key: real-openai-key
+      rpm_limit: 60
+`)
+	cfg, err := LoadPaths(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast := cfg.Models["fast"]
+	if fast.APIKey != "real-gemini-key" {
+		t.Errorf("overlay key not applied: %q", fast.APIKey)
+	}
+	if fast.Provider != "gemini" || !fast.SupportsTools {
+		t.Errorf("base fields lost in merge: %+v", fast)
+	}
+	if cfg.Models["smart"].RPMLimit != 60 {
+		t.Errorf("overlay-only field lost: %+v", cfg.Models["smart"])
+	}
+	if cfg.DefaultModel != "fast" {
+		t.Errorf("base-only top-level key lost: %q", cfg.DefaultModel)
+	}
+}
+
+func TestDefaultPathColonSeparatedList(t *testing.T) {
+	base := writeConfig(t, `
+llm:
+  models:
+    m: {provider: openai, model: gpt-4o, api_key: placeholder}
+`)
+	overlay := writeConfig(t, `
+llm:
+  models:
+    m: {api_key: real}
+`)
+	t.Setenv("LLM_CONFIG_PATH", base+string(os.PathListSeparator)+overlay)
+	cfg, err := LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Models["m"].APIKey != "real" {
+		t.Errorf("merged default load = %+v", cfg.Models["m"])
+	}
+}
@@ -0,0 +1,57 @@
+// Package convtest generates random valid canonical conversations for
+// fuzzing the provider serializers. "Valid" means the conversations uphold
+// the contract documented on core.Message: legal role sequences and tool
+// calls always paired with matching results.
+package convtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+var sampleTexts = []string{"", "hello", "a longer piece of text", "émoji ✨", "{\"looks\":\"like json\"}"}
+
+// Conversation produces a random valid conversation of roughly n turns
+// derived deterministically from r.
+func Conversation(r *rand.Rand, n int) []core.Message {
+	var msgs []core.Message
+	if r.Intn(2) == 0 {
+		msgs = append(msgs, core.Message{Role: core.RoleSystem, Content: "system prompt"})
+	}
+	callCounter := 0
+	for i := 0; i < n; i++ {
+		user := core.Message{Role: core.RoleUser, Content: sampleTexts[r.Intn(len(sampleTexts))]}
+		if r.Intn(4) == 0 {
+			user.Images = append(user.Images, fmt.Sprintf("https://example.com/img%d.png", i))
+		}
+		msgs = append(msgs, user)
+
+		if r.Intn(3) == 0 {
+			// An assistant tool-call turn followed by its paired results.
+			numCalls := 1 + r.Intn(2)
+			call := core.Message{Role: core.RoleAssistant}
+			result := core.Message{Role: core.RoleTool}
+			for c := 0; c < numCalls; c++ {
+				callCounter++
+				id := fmt.Sprintf("call_%d", callCounter)
+				name := fmt.Sprintf("tool_%d", r.Intn(3))
+				call.ToolCalls = append(call.ToolCalls, core.ToolCall{
+					CallID: id,
+					Name:   name,
+					Args:   json.RawMessage(fmt.Sprintf(`{"x": %d}`, r.Intn(100))),
+				})
+				result.ToolResults = append(result.ToolResults, core.ToolResult{
+					CallID:  id,
+					Name:    name,
+					Content: map[string]any{"ok": true},
+				})
+			}
+			msgs = append(msgs, call, result)
+		}
+		msgs = append(msgs, core.Message{Role: core.RoleAssistant, Content: sampleTexts[r.Intn(len(sampleTexts))]})
+	}
+	return msgs
+}
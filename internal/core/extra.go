@@ -0,0 +1,26 @@
+package core
+
+import "log/slog"
+
+// MergeExtra deep-merges provider-specific extra options into an outgoing
+// payload after the standard fields are built. Nested maps merge
+// recursively; a key whose value the router already manages (present and
+// not a map on both sides) is skipped with a loud log so the escape hatch
+// cannot silently corrupt router-built fields like messages or tools.
+func MergeExtra(payload, extra map[string]any, logger *slog.Logger, provider string) {
+	for k, v := range extra {
+		existing, present := payload[k]
+		if !present {
+			payload[k] = v
+			continue
+		}
+		existingMap, isMap := existing.(map[string]any)
+		extraMap, extraIsMap := v.(map[string]any)
+		if isMap && extraIsMap {
+			MergeExtra(existingMap, extraMap, logger, provider)
+			continue
+		}
+		logger.Warn("provider option collides with a router-managed field; ignoring",
+			"provider", provider, "key", k)
+	}
+}
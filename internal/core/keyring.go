@@ -0,0 +1,73 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimitCooldown is how long a key sits out after a 429.
+const rateLimitCooldown = 30 * time.Second
+
+// KeyRing rotates through a model's API keys round-robin, sitting a key out
+// for a cooldown period after it is rate limited. It is safe for concurrent
+// use.
+type KeyRing struct {
+	mu           sync.Mutex
+	keys         []string
+	next         int
+	limitedUntil []time.Time
+	now          func() time.Time
+}
+
+// NewKeyRing builds a ring over the given keys. Empty keys are permitted
+// (keyless providers) but never rotated around.
+func NewKeyRing(keys ...string) *KeyRing {
+	return &KeyRing{
+		keys:         keys,
+		limitedUntil: make([]time.Time, len(keys)),
+		now:          time.Now,
+	}
+}
+
+// Pick returns the next usable key and its index. Keys cooling down after a
+// rate limit are skipped; when every key is cooling down, plain round-robin
+// resumes rather than blocking.
+func (k *KeyRing) Pick() (string, int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.keys) == 0 {
+		return "", 0
+	}
+	now := k.now()
+	for offset := 0; offset < len(k.keys); offset++ {
+		i := (k.next + offset) % len(k.keys)
+		if now.After(k.limitedUntil[i]) {
+			k.next = i + 1
+			return k.keys[i], i
+		}
+	}
+	i := k.next % len(k.keys)
+	k.next = i + 1
+	return k.keys[i], i
+}
+
+// MarkRateLimited sidelines the key at index i for the cooldown period.
+func (k *KeyRing) MarkRateLimited(i int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if i >= 0 && i < len(k.limitedUntil) {
+		k.limitedUntil[i] = k.now().Add(rateLimitCooldown)
+	}
+}
+
+// All returns every key in the ring, for redaction.
+func (k *KeyRing) All() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	out := make([]string, len(k.keys))
+	copy(out, k.keys)
+	return out
+}
+
+// Len reports how many keys the ring holds.
+func (k *KeyRing) Len() int { return len(k.keys) }
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyRingRoundRobin(t *testing.T) {
+	k := NewKeyRing("a", "b", "c")
+	var got []string
+	for i := 0; i < 6; i++ {
+		key, _ := k.Pick()
+		got = append(got, key)
+	}
+	want := []string{"a", "b", "c", "a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rotation = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestKeyRingCooldown(t *testing.T) {
+	k := NewKeyRing("a", "b")
+	now := time.Now()
+	k.now = func() time.Time { return now }
+
+	_, i := k.Pick() // "a"
+	k.MarkRateLimited(i)
+	for n := 0; n < 3; n++ {
+		key, _ := k.Pick()
+		if key == "a" {
+			t.Fatal("rate-limited key used during cooldown")
+		}
+	}
+
+	now = now.Add(rateLimitCooldown + time.Second)
+	seen := map[string]bool{}
+	for n := 0; n < 2; n++ {
+		key, _ := k.Pick()
+		seen[key] = true
+	}
+	if !seen["a"] {
+		t.Error("key should rejoin the rotation after cooldown")
+	}
+}
+
+func TestKeyRingAllLimitedStillServes(t *testing.T) {
+	k := NewKeyRing("a", "b")
+	k.MarkRateLimited(0)
+	k.MarkRateLimited(1)
+	if key, _ := k.Pick(); key == "" {
+		t.Error("ring must keep serving keys when all are cooling down")
+	}
+}
@@ -0,0 +1,388 @@
+// Package core defines the provider-agnostic types exchanged between the
+// router and the provider clients. Provider packages translate these into
+// their wire formats; the router never sees provider-specific payloads.
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Role identifies the author of a Message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	// RoleTool marks messages carrying tool results back to the model.
+	RoleTool Role = "tool"
+)
+
+// Message is one entry in a conversation, in the router's canonical form.
+// Provider clients map it to their own message/content representation.
+//
+// Messages containing ToolCalls and ToolResults are produced by the tool
+// loop, but callers may also supply them directly — for example to resume a
+// persisted conversation — and they map to the same provider payloads the
+// loop would have produced.
+//
+// The canonical form is the single source of truth for provider mapping.
+// Every serializer must uphold this contract for any valid conversation:
+//
+//   - only roles the provider's API defines are emitted, with RoleSystem
+//     relocated (not dropped) where the provider has no system role;
+//   - no empty content blocks: a message with no content, images, calls, or
+//     results produces nothing rather than an empty part;
+//   - tool pairing stays intact: every emitted tool result refers to an
+//     earlier emitted call of the same tool, in order, and call IDs are
+//     carried through verbatim;
+//   - serialization is pure: mapping the same conversation twice yields
+//     identical payloads.
+type Message struct {
+	Role    Role
+	Content string
+	// Images holds URLs of images attached to the message.
+	Images []string
+	// ImageData holds in-memory images attached to the message, mapped to
+	// Gemini inlineData and OpenAI data-URL image parts.
+	ImageData []ImageData
+	// Files holds document attachments (PDF and similar), by URI or bytes.
+	// Requires a model with supports_files; see FileAttachment for the
+	// provider mapping and its limits.
+	Files []FileAttachment
+	// Audio holds audio input attachments. Requires a model with
+	// supports_audio.
+	Audio []AudioData
+	// ToolCalls is set on assistant messages that requested tool
+	// invocations; each entry pairs with a ToolResult by CallID.
+	ToolCalls []ToolCall
+	// ToolResults is set on RoleTool messages carrying the results of tool
+	// invocations back to the model.
+	ToolResults []ToolResult
+}
+
+// ImageData is an in-memory image attachment.
+type ImageData struct {
+	// MIME is the image's content type, e.g. "image/png".
+	MIME string
+	Data []byte
+}
+
+// DataURI renders the image as a data: URI for providers that take URLs.
+func (d ImageData) DataURI() string {
+	return "data:" + d.MIME + ";base64," + base64.StdEncoding.EncodeToString(d.Data)
+}
+
+// FileAttachment is a document attached to a message, supplied either by
+// URI or as bytes with a MIME type. Gemini maps URIs to fileData and bytes
+// to inlineData; OpenAI accepts bytes as base64 file parts but has no URI
+// form, so URI attachments there fail with ErrUnsupportedAttachment rather
+// than being dropped silently.
+type FileAttachment struct {
+	// MIME is the document's content type, e.g. "application/pdf".
+	MIME string
+	// Name is the document's filename. OpenAI requires one alongside the
+	// file data; when empty, one is synthesized from the MIME type.
+	Name string
+	// URI points at externally hosted content. Mutually exclusive with Data.
+	URI string
+	// Data is the document's raw content.
+	Data []byte
+}
+
+// Filename returns Name, or a placeholder derived from the MIME subtype
+// ("attachment.pdf" for application/pdf) for providers that insist on one.
+func (f FileAttachment) Filename() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	if _, subtype, ok := strings.Cut(f.MIME, "/"); ok && subtype != "" {
+		return "attachment." + subtype
+	}
+	return "attachment"
+}
+
+// DataURI renders the attachment as a data: URI.
+func (f FileAttachment) DataURI() string {
+	return "data:" + f.MIME + ";base64," + base64.StdEncoding.EncodeToString(f.Data)
+}
+
+// AudioData is an in-memory audio attachment, mapped to Gemini inlineData
+// and OpenAI input_audio parts.
+type AudioData struct {
+	// Format is the container format: "wav", "mp3", and similar.
+	Format string
+	Data   []byte
+}
+
+// MIME returns the audio content type for providers that take MIME types.
+func (a AudioData) MIME() string { return "audio/" + a.Format }
+
+// ErrAttachmentTooLarge is returned before sending when an inline
+// attachment exceeds the provider's size limit.
+var ErrAttachmentTooLarge = errors.New("attachment exceeds provider size limit")
+
+// ErrUnsupportedAttachment is returned before sending when the selected
+// provider cannot accept an attachment in the supplied form.
+var ErrUnsupportedAttachment = errors.New("provider does not support this attachment")
+
+// ErrAuthFailed marks provider rejections of the configured credentials.
+var ErrAuthFailed = errors.New("provider rejected the API key")
+
+// ErrModelNotFound marks provider responses saying the underlying model
+// name does not exist.
+var ErrModelNotFound = errors.New("provider does not know the model")
+
+// ToolCall is a single tool invocation requested by the model.
+type ToolCall struct {
+	// CallID is the provider-assigned identifier pairing this call with its
+	// result. May be empty for providers that do not return one.
+	CallID string
+	// Name is the tool's name as declared in its ToolDef.
+	Name string
+	// Args is the raw JSON arguments object the model supplied.
+	Args json.RawMessage
+}
+
+// ToolResult carries the outcome of executing a ToolCall back to the model.
+type ToolResult struct {
+	// CallID pairs the result with the originating ToolCall.
+	CallID string
+	// Name is the executed tool's name.
+	Name string
+	// Content is the tool's return value; it is serialized to JSON for the
+	// provider.
+	Content any
+}
+
+// ToolParameter describes one parameter of a tool, derived by reflection from
+// the tool's Parameters struct.
+type ToolParameter struct {
+	Name        string
+	Description string
+	Required    bool
+	// Nullable marks parameters (pointer fields) for which an explicit JSON
+	// null is an acceptable value.
+	Nullable bool
+	// Schema is the JSON Schema fragment for the parameter value,
+	// e.g. {"type": "string"}.
+	Schema map[string]any
+}
+
+// ToolDef is the provider-agnostic definition of a tool, sent to the model so
+// it can decide when and how to call it.
+type ToolDef struct {
+	Name        string
+	Description string
+	Parameters  []ToolParameter
+	// RawSchema, when non-nil, is a complete JSON Schema used verbatim for
+	// the tool's parameters instead of assembling one from Parameters.
+	// Tools that already carry a JSON Schema (e.g. bridged MCP tools) use
+	// this path.
+	RawSchema map[string]any
+}
+
+// Usage reports token consumption for a single provider call.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Add accumulates o into u.
+func (u *Usage) Add(o Usage) {
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.TotalTokens += o.TotalTokens
+}
+
+// CallParams is everything a provider client needs to perform one model call.
+type CallParams struct {
+	Model string
+	// RequestID correlates provider-side records and client logs with the
+	// router execution; forwarded where the provider supports it.
+	RequestID string
+	// System is the request-level system prompt. Providers place it where
+	// their API expects (systemInstruction for Gemini, a leading system
+	// message for OpenAI), merging with any explicit system messages.
+	System   string
+	Messages []Message
+	// Temperature, TopP, TopK, and MaxTokens are pointers so providers can
+	// distinguish an explicit zero (emit it) from unset (omit the field and
+	// let the provider default apply). TopK is ignored silently by
+	// providers without the knob (OpenAI).
+	Temperature *float64
+	TopP        *float64
+	TopK        *int
+	MaxTokens   *int
+	// Seed requests deterministic sampling where the provider supports it.
+	// A pointer so that seed 0 is distinguishable from unset.
+	Seed *int64
+	// CandidateCount asks the provider for several alternative completions
+	// (OpenAI n, Gemini candidateCount). Zero means the provider default of
+	// one.
+	CandidateCount int
+	// OutputSchema, when non-empty, is a sanitized JSON Schema the model's
+	// response must conform to. Providers that support structured output
+	// enforce it natively; others ignore it.
+	OutputSchema string
+	// ForceJSON requests the provider's schema-free JSON mode even when no
+	// OutputSchema is supplied.
+	ForceJSON bool
+	// StrictJSONSchema marks models whose provider enforces OutputSchema
+	// natively with strict mode (OpenAI json_schema); without it the
+	// schema degrades to the provider's softer JSON mode.
+	StrictJSONSchema bool
+	ToolDefs         []ToolDef
+	// ToolChoice controls whether and which tools the model may call; the
+	// zero value leaves the provider default ("auto").
+	ToolChoice ToolChoice
+	// StrictTools requests provider-enforced function schemas (OpenAI
+	// strict: true); only set for models whose capability flags say they
+	// accept it.
+	StrictTools bool
+	// Extra holds provider-specific options deep-merged into the outgoing
+	// payload after the standard fields; see MergeExtra.
+	Extra map[string]any
+}
+
+// ToolChoice constrains the model's tool usage for one call.
+type ToolChoice struct {
+	// Mode is "", "auto", "none", "required", or "tool".
+	Mode string
+	// Name is the forced tool when Mode is "tool".
+	Name string
+}
+
+// FinishReason reports why the model stopped generating, normalized across
+// providers.
+type FinishReason string
+
+const (
+	// FinishStop is a natural completion.
+	FinishStop FinishReason = "stop"
+	// FinishLength means generation was cut off by the token limit.
+	FinishLength FinishReason = "length"
+	// FinishToolCalls means the model stopped to request tool executions.
+	FinishToolCalls FinishReason = "tool_calls"
+	// FinishContentFilter means the provider suppressed the output.
+	FinishContentFilter FinishReason = "content_filter"
+	// FinishUnknown is used when the provider reported nothing recognizable.
+	FinishUnknown FinishReason = ""
+)
+
+// Timing is the latency breakdown of one provider call, separating
+// network/queueing from generation.
+type Timing struct {
+	// TTFB is the time from sending the request to the first response byte
+	// (headers). Slowness here is network or provider queueing.
+	TTFB time.Duration
+	// TTFT is the time to the first content token; only set by streaming
+	// clients.
+	TTFT time.Duration
+	// Total is the full request duration including reading the body.
+	Total time.Duration
+}
+
+// Candidate is one alternative completion when multiple candidates are
+// requested.
+type Candidate struct {
+	Content      string
+	FinishReason FinishReason
+}
+
+// RawResponse is the provider-agnostic result of one model call. Content,
+// ToolCalls, and FinishReason always describe the first candidate; when the
+// caller requested several, Candidates holds all of them and Usage covers
+// the combined completion tokens.
+type RawResponse struct {
+	Content      string
+	ToolCalls    []ToolCall
+	Usage        Usage
+	FinishReason FinishReason
+	Timing       Timing
+	Candidates   []Candidate
+}
+
+// PayloadRecorder receives redacted copies of the exact wire payloads a
+// provider client sent and received, with the HTTP status (0 when the
+// request never completed). API keys are scrubbed before delivery.
+type PayloadRecorder func(provider string, req, resp []byte, status int)
+
+// RawClient is implemented by each provider package.
+type RawClient interface {
+	Call(ctx context.Context, p CallParams) (*RawResponse, error)
+}
+
+// Pinger is optionally implemented by provider clients that can cheaply
+// verify reachability, credentials, and the existence of a model.
+type Pinger interface {
+	Ping(ctx context.Context, model string) error
+}
+
+// TokenCounter is optionally implemented by provider clients whose API can
+// count prompt tokens exactly (e.g. Gemini's :countTokens). Clients without
+// it fall back to the router's local estimator.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, p CallParams) (int, error)
+}
+
+// WarmableClient is optionally implemented by provider clients that can
+// establish connections (TLS handshake, HTTP/2 setup) ahead of the first
+// real call.
+type WarmableClient interface {
+	Warm(ctx context.Context) error
+}
+
+// StreamingClient is optionally implemented by provider clients that can
+// stream content. onDelta is invoked for each content fragment as it
+// arrives; the returned RawResponse carries the complete result exactly as a
+// non-streaming Call would. Clients that cannot stream simply don't
+// implement this, and callers fall back to Call.
+type StreamingClient interface {
+	CallStream(ctx context.Context, p CallParams, onDelta func(string)) (*RawResponse, error)
+}
+
+// GenerateJSONSchemaFromToolDef assembles the JSON Schema object describing a
+// tool's parameters, in the form both providers' function-calling APIs expect.
+func GenerateJSONSchemaFromToolDef(def ToolDef) map[string]any {
+	if def.RawSchema != nil {
+		return def.RawSchema
+	}
+	properties := make(map[string]any, len(def.Parameters))
+	var required []string
+	for _, p := range def.Parameters {
+		schema := p.Schema
+		// Merge the parameter description into the property schema; it is
+		// tracked separately on ToolParameter and would otherwise never
+		// reach the provider. Copy first: cached schema fragments are
+		// shared and must stay unmutated.
+		if p.Description != "" {
+			merged := make(map[string]any, len(schema)+1)
+			for k, v := range schema {
+				merged[k] = v
+			}
+			if _, ok := merged["description"]; !ok {
+				merged["description"] = p.Description
+			}
+			schema = merged
+		}
+		properties[p.Name] = schema
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
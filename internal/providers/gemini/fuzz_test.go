@@ -0,0 +1,54 @@
+package gemini
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core/convtest"
+)
+
+// FuzzMapMessages asserts the serializer contract documented on
+// core.Message holds for arbitrary valid conversations: legal roles, no
+// empty parts, and function responses paired in order with earlier calls.
+func FuzzMapMessages(f *testing.F) {
+	for _, seed := range []int64{0, 1, 2, 42, 1234} {
+		f.Add(seed, 6)
+	}
+	f.Fuzz(func(t *testing.T, seed int64, n int) {
+		if n < 0 {
+			n = -n
+		}
+		msgs := convtest.Conversation(rand.New(rand.NewSource(seed)), n%10)
+		contents := mapMessages(msgs)
+
+		var pendingCalls []string
+		for i, c := range contents {
+			if c.Role != "user" && c.Role != "model" {
+				t.Fatalf("content %d has illegal role %q", i, c.Role)
+			}
+			if len(c.Parts) == 0 {
+				t.Fatalf("content %d (%s) has no parts", i, c.Role)
+			}
+			for _, p := range c.Parts {
+				if p.Text == "" && p.FileData == nil && p.FunctionCall == nil && p.FunctionResponse == nil {
+					t.Fatalf("content %d contains an empty part", i)
+				}
+				if p.FunctionCall != nil {
+					pendingCalls = append(pendingCalls, p.FunctionCall.Name)
+				}
+				if p.FunctionResponse != nil {
+					if len(pendingCalls) == 0 {
+						t.Fatalf("functionResponse %q without a preceding call", p.FunctionResponse.Name)
+					}
+					if pendingCalls[0] != p.FunctionResponse.Name {
+						t.Fatalf("functionResponse %q out of order, expected %q", p.FunctionResponse.Name, pendingCalls[0])
+					}
+					pendingCalls = pendingCalls[1:]
+				}
+			}
+		}
+		if len(pendingCalls) != 0 {
+			t.Fatalf("%d function calls left without responses", len(pendingCalls))
+		}
+	})
+}
@@ -0,0 +1,655 @@
+// Package gemini implements the router's client for the Gemini
+// generateContent API.
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/retry"
+)
+
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// Client talks to the Gemini API.
+type Client struct {
+	keys     *core.KeyRing
+	baseURL  string
+	http     *http.Client
+	logger   *slog.Logger
+	verbose  bool
+	recorder core.PayloadRecorder
+	headers  map[string]string
+	retryCfg retry.Config
+}
+
+// SetRetryConfig overrides the default retry policy for this client.
+func (c *Client) SetRetryConfig(cfg retry.Config) { c.retryCfg = cfg }
+
+// SetHeaders installs extra HTTP headers applied to every outgoing
+// request. Header values are treated as secrets and never logged.
+func (c *Client) SetHeaders(h map[string]string) { c.headers = h }
+
+// applyHeaders stamps the configured extra headers onto req.
+func (c *Client) applyHeaders(req *http.Request) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// SetPayloadRecorder installs a recorder receiving redacted request and
+// response payloads for every call.
+func (c *Client) SetPayloadRecorder(fn core.PayloadRecorder) { c.recorder = fn }
+
+// setAPIKey authenticates a request and returns the picked key's ring
+// index. The key travels in the x-goog-api-key header, never in the URL:
+// transport failures wrap the full URL into url.Error messages that end up
+// in returned errors and retry logs, so a query-string key would leak.
+func (c *Client) setAPIKey(req *http.Request) int {
+	key, keyIndex := c.keys.Pick()
+	if c.keys.Len() > 1 {
+		c.logger.Debug("using api key", "key_index", keyIndex)
+	}
+	req.Header.Set("x-goog-api-key", key)
+	return keyIndex
+}
+
+// record hands redacted payloads to the recorder, scrubbing the API key
+// wherever it might appear.
+func (c *Client) record(req, resp []byte, status int) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder("gemini", c.redact(req), c.redact(resp), status)
+}
+
+func (c *Client) redact(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	for _, key := range c.keys.All() {
+		if key != "" {
+			b = bytes.ReplaceAll(b, []byte(key), []byte("[REDACTED]"))
+		}
+	}
+	return b
+}
+
+// New builds a Client rotating round-robin over the given API keys.
+// baseURL may be empty to use the public API.
+func New(keys []string, baseURL string, logger *slog.Logger) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		keys:     core.NewKeyRing(keys...),
+		baseURL:  baseURL,
+		http:     &http.Client{},
+		logger:   logger,
+		verbose:  os.Getenv("LLM_VERBOSE_MESSAGES") == "1",
+		retryCfg: retry.Default,
+	}
+}
+
+type gemContent struct {
+	Role  string    `json:"role,omitempty"`
+	Parts []gemPart `json:"parts"`
+}
+
+type gemPart struct {
+	Text             string           `json:"text,omitempty"`
+	FileData         *gemFileData     `json:"fileData,omitempty"`
+	InlineData       *gemInlineData   `json:"inlineData,omitempty"`
+	FunctionCall     *gemFunctionCall `json:"functionCall,omitempty"`
+	FunctionResponse *gemFunctionResp `json:"functionResponse,omitempty"`
+}
+
+type gemFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+type gemInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// maxInlineBytes is Gemini's limit for inline (base64) attachments.
+const maxInlineBytes = 20 << 20
+
+type gemFunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type gemFunctionResp struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+// Warm implements core.WarmableClient by issuing a cheap GET so connection
+// setup happens before the first real call. Non-2xx statuses are not errors
+// here; only transport failures are.
+func (c *Client) Warm(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", nil)
+	if err != nil {
+		return err
+	}
+	c.setAPIKey(req)
+	c.applyHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// buildPayload assembles the generateContent request body.
+func (c *Client) buildPayload(p core.CallParams) map[string]any {
+	system, rest := splitSystem(p.System, p.Messages)
+	payload := map[string]any{
+		"contents": mapMessages(rest),
+	}
+	if len(system) > 0 {
+		var parts []gemPart
+		for _, s := range system {
+			parts = append(parts, gemPart{Text: s})
+		}
+		payload["systemInstruction"] = gemContent{Parts: parts}
+	}
+	genConfig := map[string]any{}
+	if p.Temperature != nil {
+		genConfig["temperature"] = *p.Temperature
+	}
+	if p.TopP != nil {
+		genConfig["topP"] = *p.TopP
+	}
+	if p.TopK != nil {
+		genConfig["topK"] = *p.TopK
+	}
+	if p.MaxTokens != nil {
+		genConfig["maxOutputTokens"] = *p.MaxTokens
+	}
+	if p.Seed != nil {
+		genConfig["seed"] = *p.Seed
+	}
+	if p.CandidateCount > 1 {
+		genConfig["candidateCount"] = p.CandidateCount
+	}
+	if p.OutputSchema != "" || p.ForceJSON {
+		genConfig["responseMimeType"] = "application/json"
+	}
+	if p.OutputSchema != "" {
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(p.OutputSchema), &schema); err == nil {
+			genConfig["responseSchema"] = toGeminiSchema(schema)
+		}
+	}
+	if len(genConfig) > 0 {
+		payload["generationConfig"] = genConfig
+	}
+	if len(p.ToolDefs) > 0 {
+		payload["tools"] = []map[string]any{{"functionDeclarations": mapTools(p.ToolDefs)}}
+		if fcc := functionCallingConfig(p.ToolChoice); fcc != nil {
+			payload["toolConfig"] = map[string]any{"functionCallingConfig": fcc}
+		}
+	}
+	if len(p.Extra) > 0 {
+		core.MergeExtra(payload, p.Extra, c.logger, "gemini")
+	}
+	return payload
+}
+
+// CountTokens implements core.TokenCounter using the :countTokens endpoint,
+// which returns the exact prompt token count. The payload mirrors what a
+// generateContent call would send — system instruction and tool
+// definitions included — so the count covers everything the model would
+// actually see.
+func (c *Client) CountTokens(ctx context.Context, p core.CallParams) (int, error) {
+	system, rest := splitSystem(p.System, p.Messages)
+	payload := map[string]any{"contents": mapMessages(rest)}
+	if len(system) > 0 {
+		var parts []gemPart
+		for _, s := range system {
+			parts = append(parts, gemPart{Text: s})
+		}
+		payload["systemInstruction"] = gemContent{Parts: parts}
+	}
+	if len(p.ToolDefs) > 0 {
+		payload["tools"] = []map[string]any{{"functionDeclarations": mapTools(p.ToolDefs)}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	url := fmt.Sprintf("%s/models/%s:countTokens", c.baseURL, p.Model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAPIKey(req)
+	c.applyHeaders(req)
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("gemini: countTokens status %d: %s", httpResp.StatusCode, truncate(respBody, 512))
+	}
+	var parsed struct {
+		TotalTokens int `json:"totalTokens"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return 0, fmt.Errorf("gemini: parsing countTokens response: %w", err)
+	}
+	return parsed.TotalTokens, nil
+}
+
+// validateAttachments rejects inline attachments over Gemini's size limit
+// before anything goes on the wire.
+func validateAttachments(p core.CallParams) error {
+	for _, m := range p.Messages {
+		for _, img := range m.ImageData {
+			if len(img.Data) > maxInlineBytes {
+				return fmt.Errorf("%w: gemini inline image of %d bytes (limit %d)",
+					core.ErrAttachmentTooLarge, len(img.Data), maxInlineBytes)
+			}
+		}
+		for _, f := range m.Files {
+			if len(f.Data) > maxInlineBytes {
+				return fmt.Errorf("%w: gemini inline file of %d bytes (limit %d)",
+					core.ErrAttachmentTooLarge, len(f.Data), maxInlineBytes)
+			}
+		}
+	}
+	return nil
+}
+
+// Ping implements core.Pinger with a single GET /models/{model} — the
+// cheapest call that verifies the key and the model's existence. It never
+// retries.
+func (c *Client) Ping(ctx context.Context, model string) error {
+	url := fmt.Sprintf("%s/models/%s", c.baseURL, model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	c.setAPIKey(req)
+	c.applyHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gemini: ping: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: gemini status %d", core.ErrAuthFailed, resp.StatusCode)
+	case resp.StatusCode == http.StatusBadRequest && bytes.Contains(body, []byte("API key")):
+		return fmt.Errorf("%w: gemini status %d", core.ErrAuthFailed, resp.StatusCode)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: gemini model %q", core.ErrModelNotFound, model)
+	default:
+		return fmt.Errorf("gemini: ping status %d: %s", resp.StatusCode, truncate(body, 256))
+	}
+}
+
+// Call implements core.RawClient.
+func (c *Client) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	if err := validateAttachments(p); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(c.buildPayload(p))
+	if err != nil {
+		return nil, err
+	}
+	logger := c.logger
+	if p.RequestID != "" {
+		logger = logger.With("request_id", p.RequestID)
+	}
+	if c.verbose {
+		logger.Debug("gemini request payload", "body", string(body))
+	}
+
+	var resp *core.RawResponse
+	err = retry.Do(ctx, c.retryCfg, logger, func() error {
+		var callErr error
+		resp, callErr = c.doCall(ctx, p.Model, body, p.RequestID)
+		return callErr
+	})
+	return resp, err
+}
+
+func (c *Client) doCall(ctx context.Context, model string, body []byte, requestID string) (*core.RawResponse, error) {
+	start := time.Now()
+	var ttfb time.Duration
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { ttfb = time.Since(start) },
+	})
+	url := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	keyIndex := c.setAPIKey(req)
+	req.Header.Set("Content-Type", "application/json")
+	if requestID != "" {
+		req.Header.Set("X-Request-Id", requestID)
+	}
+	c.applyHeaders(req)
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		c.record(body, nil, 0)
+		return nil, retry.Transient(err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		c.record(body, nil, httpResp.StatusCode)
+		return nil, retry.Transient(err)
+	}
+	c.record(body, respBody, httpResp.StatusCode)
+	if httpResp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: gemini status 404: %s", core.ErrModelNotFound, truncate(respBody, 256))
+	}
+	if httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: gemini status %d", core.ErrAuthFailed, httpResp.StatusCode)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("gemini: status %d: %s", httpResp.StatusCode, truncate(respBody, 512))
+		if httpResp.StatusCode == http.StatusTooManyRequests {
+			c.keys.MarkRateLimited(keyIndex)
+			c.logger.Warn("api key rate limited, cooling down", "key_index", keyIndex)
+		}
+		if httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500 {
+			return nil, retry.Transient(err)
+		}
+		return nil, err
+	}
+	resp, err := parseResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+	resp.Timing = core.Timing{TTFB: ttfb, Total: time.Since(start)}
+	return resp, nil
+}
+
+func parseResponse(body []byte) (*core.RawResponse, error) {
+	var parsed struct {
+		Candidates []struct {
+			Content      gemContent `json:"content"`
+			FinishReason string     `json:"finishReason"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			TotalTokenCount      int `json:"totalTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("gemini: parsing response: %w", err)
+	}
+	if len(parsed.Candidates) == 0 {
+		return nil, fmt.Errorf("gemini: response contained no candidates")
+	}
+	out := &core.RawResponse{
+		FinishReason: mapFinishReason(parsed.Candidates[0].FinishReason),
+		Usage: core.Usage{
+			PromptTokens:     parsed.UsageMetadata.PromptTokenCount,
+			CompletionTokens: parsed.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      parsed.UsageMetadata.TotalTokenCount,
+		},
+	}
+	for _, part := range parsed.Candidates[0].Content.Parts {
+		if part.Text != "" {
+			out.Content += part.Text
+		}
+		if part.FunctionCall != nil {
+			out.FinishReason = core.FinishToolCalls
+			args, _ := json.Marshal(part.FunctionCall.Args)
+			out.ToolCalls = append(out.ToolCalls, core.ToolCall{
+				Name: part.FunctionCall.Name,
+				Args: args,
+			})
+		}
+	}
+	for _, cand := range parsed.Candidates {
+		c := core.Candidate{FinishReason: mapFinishReason(cand.FinishReason)}
+		for _, part := range cand.Content.Parts {
+			c.Content += part.Text
+		}
+		out.Candidates = append(out.Candidates, c)
+	}
+	return out, nil
+}
+
+// mapFinishReason normalizes Gemini's finishReason values.
+func mapFinishReason(s string) core.FinishReason {
+	switch s {
+	case "STOP":
+		return core.FinishStop
+	case "MAX_TOKENS":
+		return core.FinishLength
+	case "SAFETY", "PROHIBITED_CONTENT", "BLOCKLIST":
+		return core.FinishContentFilter
+	default:
+		return core.FinishUnknown
+	}
+}
+
+// splitSystem collects the request-level system prompt and any explicit
+// system messages (deduplicated) for Gemini's systemInstruction field,
+// returning the remaining conversation.
+func splitSystem(reqSystem string, msgs []core.Message) ([]string, []core.Message) {
+	var system []string
+	if reqSystem != "" {
+		system = append(system, reqSystem)
+	}
+	rest := make([]core.Message, 0, len(msgs))
+	for _, m := range msgs {
+		if m.Role == core.RoleSystem {
+			if m.Content != "" && m.Content != reqSystem {
+				system = append(system, m.Content)
+			}
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return system, rest
+}
+
+// mapMessages converts canonical messages to Gemini contents; system
+// messages are expected to have been split out into systemInstruction by
+// buildPayload.
+func mapMessages(msgs []core.Message) []gemContent {
+	var out []gemContent
+	for _, m := range msgs {
+		switch {
+		case len(m.ToolResults) > 0:
+			var parts []gemPart
+			for _, tr := range m.ToolResults {
+				parts = append(parts, gemPart{FunctionResponse: &gemFunctionResp{
+					Name:     tr.Name,
+					Response: map[string]any{"result": tr.Content},
+				}})
+			}
+			out = append(out, gemContent{Role: "user", Parts: parts})
+		case len(m.ToolCalls) > 0:
+			var parts []gemPart
+			if m.Content != "" {
+				parts = append(parts, gemPart{Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				var args map[string]any
+				_ = json.Unmarshal(tc.Args, &args)
+				parts = append(parts, gemPart{FunctionCall: &gemFunctionCall{
+					Name: tc.Name,
+					Args: args,
+				}})
+			}
+			out = append(out, gemContent{Role: "model", Parts: parts})
+		default:
+			role := "user"
+			if m.Role == core.RoleAssistant {
+				role = "model"
+			}
+			// Gemini rejects empty parts; only emit the text part when there
+			// is text, and skip the message entirely when nothing remains.
+			var parts []gemPart
+			if m.Content != "" {
+				parts = append(parts, gemPart{Text: m.Content})
+			}
+			for _, url := range m.Images {
+				parts = append(parts, gemPart{FileData: &gemFileData{FileURI: url}})
+			}
+			for _, img := range m.ImageData {
+				parts = append(parts, gemPart{InlineData: &gemInlineData{
+					MimeType: img.MIME,
+					Data:     base64.StdEncoding.EncodeToString(img.Data),
+				}})
+			}
+			for _, f := range m.Files {
+				if f.URI != "" {
+					parts = append(parts, gemPart{FileData: &gemFileData{MimeType: f.MIME, FileURI: f.URI}})
+				} else {
+					parts = append(parts, gemPart{InlineData: &gemInlineData{
+						MimeType: f.MIME,
+						Data:     base64.StdEncoding.EncodeToString(f.Data),
+					}})
+				}
+			}
+			for _, au := range m.Audio {
+				parts = append(parts, gemPart{InlineData: &gemInlineData{
+					MimeType: au.MIME(),
+					Data:     base64.StdEncoding.EncodeToString(au.Data),
+				}})
+			}
+			if len(parts) > 0 {
+				out = append(out, gemContent{Role: role, Parts: parts})
+			}
+		}
+	}
+	return out
+}
+
+func mapTools(defs []core.ToolDef) []map[string]any {
+	out := make([]map[string]any, 0, len(defs))
+	for _, d := range defs {
+		out = append(out, map[string]any{
+			"name":        d.Name,
+			"description": d.Description,
+			"parameters":  toGeminiSchema(core.GenerateJSONSchemaFromToolDef(d)),
+		})
+	}
+	return out
+}
+
+// functionCallingConfig maps the provider-agnostic tool choice onto
+// Gemini's dialect: AUTO, NONE, or ANY, with a forced tool expressed as ANY
+// restricted to one allowed function name.
+func functionCallingConfig(tc core.ToolChoice) map[string]any {
+	switch tc.Mode {
+	case "auto":
+		return map[string]any{"mode": "AUTO"}
+	case "none":
+		return map[string]any{"mode": "NONE"}
+	case "required":
+		return map[string]any{"mode": "ANY"}
+	case "tool":
+		return map[string]any{"mode": "ANY", "allowedFunctionNames": []string{tc.Name}}
+	default:
+		return nil
+	}
+}
+
+// toGeminiSchema converts a JSON Schema fragment to Gemini's schema
+// dialect: upper-case type names, a singular example, and the subset of
+// keywords the dialect accepts. Descriptions, required arrays, enums,
+// formats, and nullability are carried through at every level — losing
+// required in particular makes models omit fields.
+func toGeminiSchema(schema map[string]any) map[string]any {
+	out := map[string]any{}
+	if ex, ok := schema["examples"].([]any); ok && len(ex) > 0 {
+		out["example"] = ex[0]
+	}
+	for _, key := range []string{"description", "title", "format", "nullable", "enum"} {
+		if v, ok := schema[key]; ok {
+			out[key] = v
+		}
+	}
+	// Gemini's schema dialect supports only a subset of the constraint
+	// keywords; the rest are dropped without erroring.
+	for _, key := range []string{"minimum", "maximum", "minItems", "maxItems"} {
+		if v, ok := schema[key]; ok {
+			out[key] = v
+		}
+	}
+	switch t, _ := schema["type"].(string); t {
+	case "string":
+		out["type"] = "STRING"
+	case "integer":
+		out["type"] = "INTEGER"
+	case "number":
+		out["type"] = "NUMBER"
+	case "boolean":
+		out["type"] = "BOOLEAN"
+	case "array":
+		out["type"] = "ARRAY"
+		if items, ok := schema["items"].(map[string]any); ok {
+			out["items"] = toGeminiSchema(items)
+		}
+	default:
+		out["type"] = "OBJECT"
+		// Gemini's schema dialect has no additionalProperties; rather than
+		// emit an invalid schema, drop the constraint and keep a hint in
+		// the description so the model still knows the value shape.
+		if ap, ok := schema["additionalProperties"].(map[string]any); ok {
+			hint := "map with values of type " + fmt.Sprint(ap["type"])
+			if d, ok := out["description"].(string); ok && d != "" {
+				out["description"] = d + " (" + hint + ")"
+			} else {
+				out["description"] = hint
+			}
+		}
+		if props, ok := schema["properties"].(map[string]any); ok {
+			conv := map[string]any{}
+			for name, sub := range props {
+				if subMap, ok := sub.(map[string]any); ok {
+					conv[name] = toGeminiSchema(subMap)
+				}
+			}
+			out["properties"] = conv
+		}
+		if required, ok := schema["required"]; ok {
+			out["required"] = required
+		}
+	}
+	return out
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) <= n {
+		return string(b)
+	}
+	return string(b[:n]) + "..."
+}
@@ -0,0 +1,38 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestParseResponseFinishReason(t *testing.T) {
+	body := []byte(`{
+		"candidates": [{"content": {"role": "model", "parts": [{"text": "cut of"}]}, "finishReason": "MAX_TOKENS"}],
+		"usageMetadata": {"promptTokenCount": 5, "candidatesTokenCount": 100, "totalTokenCount": 105}
+	}`)
+	resp, err := parseResponse(body)
+	if err != nil {
+		t.Fatalf("parseResponse: %v", err)
+	}
+	if resp.FinishReason != core.FinishLength {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, core.FinishLength)
+	}
+}
+
+func TestParseResponseFunctionCallImpliesToolCalls(t *testing.T) {
+	body := []byte(`{
+		"candidates": [{"content": {"role": "model", "parts": [{"functionCall": {"name": "f", "args": {"a": 1}}}]}, "finishReason": "STOP"}],
+		"usageMetadata": {"promptTokenCount": 5, "candidatesTokenCount": 3, "totalTokenCount": 8}
+	}`)
+	resp, err := parseResponse(body)
+	if err != nil {
+		t.Fatalf("parseResponse: %v", err)
+	}
+	if resp.FinishReason != core.FinishToolCalls {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, core.FinishToolCalls)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "f" {
+		t.Errorf("ToolCalls = %+v", resp.ToolCalls)
+	}
+}
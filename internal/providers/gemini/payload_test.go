@@ -0,0 +1,467 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestBuildPayloadJSONMode(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	base := core.CallParams{
+		Model:    "gemini-2.0-flash",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+	}
+	tests := []struct {
+		name       string
+		schema     string
+		forceJSON  bool
+		wantMime   bool
+		wantSchema bool
+	}{
+		{"plain text", "", false, false, false},
+		{"schema sets mime and responseSchema", `{"type":"object"}`, false, true, true},
+		{"force json without schema sets mime only", "", true, true, false},
+		{"schema and force json", `{"type":"object"}`, true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			p.OutputSchema = tt.schema
+			p.ForceJSON = tt.forceJSON
+			payload := c.buildPayload(p)
+			gc, _ := payload["generationConfig"].(map[string]any)
+			_, hasMime := gc["responseMimeType"]
+			_, hasSchema := gc["responseSchema"]
+			if hasMime != tt.wantMime || hasSchema != tt.wantSchema {
+				t.Errorf("mime=%v schema=%v, want mime=%v schema=%v (genConfig=%v)",
+					hasMime, hasSchema, tt.wantMime, tt.wantSchema, gc)
+			}
+		})
+	}
+}
+
+func TestBuildPayloadSystemInstruction(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	sys := "you are terse"
+
+	p := core.CallParams{System: sys, Messages: []core.Message{
+		{Role: core.RoleSystem, Content: sys},     // duplicate of the request-level prompt
+		{Role: core.RoleSystem, Content: "other"}, // distinct explicit system message
+		{Role: core.RoleUser, Content: "hi"},
+	}}
+	payload := c.buildPayload(p)
+	si, ok := payload["systemInstruction"].(gemContent)
+	if !ok {
+		t.Fatalf("systemInstruction missing: %v", payload)
+	}
+	if len(si.Parts) != 2 || si.Parts[0].Text != sys || si.Parts[1].Text != "other" {
+		t.Errorf("systemInstruction parts = %+v", si.Parts)
+	}
+	contents := payload["contents"].([]gemContent)
+	if len(contents) != 1 || contents[0].Parts[0].Text != "hi" {
+		t.Errorf("contents should hold only the conversation: %+v", contents)
+	}
+}
+
+func TestToGeminiSchemaExample(t *testing.T) {
+	in := map[string]any{
+		"type":     "string",
+		"examples": []any{"celsius"},
+	}
+	out := toGeminiSchema(in)
+	if out["example"] != "celsius" {
+		t.Errorf("example = %v, want first examples entry", out["example"])
+	}
+	if _, ok := out["examples"]; ok {
+		t.Error("JSON Schema examples array should not leak into Gemini dialect")
+	}
+}
+
+func TestBuildPayloadSeed(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}}}
+
+	gc, _ := c.buildPayload(p)["generationConfig"].(map[string]any)
+	if _, ok := gc["seed"]; ok {
+		t.Error("seed should be omitted when unset")
+	}
+	zero := int64(0)
+	p.Seed = &zero
+	gc = c.buildPayload(p)["generationConfig"].(map[string]any)
+	if got, ok := gc["seed"]; !ok || got != int64(0) {
+		t.Errorf("seed = %v (%v), want explicit 0", got, ok)
+	}
+}
+
+func TestBuildPayloadTopK(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	topK := 40
+	p := core.CallParams{Model: "m", Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}}, TopK: &topK}
+	gc := c.buildPayload(p)["generationConfig"].(map[string]any)
+	if gc["topK"] != 40 {
+		t.Errorf("topK = %v, want 40", gc["topK"])
+	}
+}
+
+func TestBuildPayloadExtraOptionsNestedMerge(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	temp := 0.3
+	p := core.CallParams{
+		Model:       "m",
+		Temperature: &temp,
+		Messages:    []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		Extra: map[string]any{
+			"generationConfig": map[string]any{
+				"responseModalities": []string{"TEXT"},
+				"temperature":        0.9, // collides with the router-managed value
+			},
+		},
+	}
+	gc := c.buildPayload(p)["generationConfig"].(map[string]any)
+	if _, ok := gc["responseModalities"]; !ok {
+		t.Error("nested extra key should merge into generationConfig")
+	}
+	if gc["temperature"] != 0.3 {
+		t.Errorf("temperature = %v, router-managed value must win", gc["temperature"])
+	}
+}
+
+func TestImageDataMapsToInlineData(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:      core.RoleUser,
+		Content:   "what is this?",
+		ImageData: []core.ImageData{{MIME: "image/png", Data: []byte{1, 2, 3}}},
+	}}}
+	contents := c.buildPayload(p)["contents"].([]gemContent)
+	if len(contents) != 1 || len(contents[0].Parts) != 2 {
+		t.Fatalf("contents = %+v", contents)
+	}
+	inline := contents[0].Parts[1].InlineData
+	if inline == nil || inline.MimeType != "image/png" || inline.Data == "" {
+		t.Errorf("inlineData = %+v", inline)
+	}
+
+	if err := validateAttachments(core.CallParams{Messages: []core.Message{{
+		ImageData: []core.ImageData{{MIME: "image/png", Data: make([]byte, maxInlineBytes+1)}},
+	}}}); err == nil {
+		t.Error("oversized inline image should be rejected before sending")
+	}
+}
+
+func TestFileAttachmentsMapping(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:    core.RoleUser,
+		Content: "summarize",
+		Files: []core.FileAttachment{
+			{MIME: "application/pdf", URI: "https://example.com/doc.pdf"},
+			{MIME: "application/pdf", Data: []byte("%PDF-")},
+		},
+	}}}
+	parts := c.buildPayload(p)["contents"].([]gemContent)[0].Parts
+	if len(parts) != 3 {
+		t.Fatalf("parts = %+v", parts)
+	}
+	if fd := parts[1].FileData; fd == nil || fd.FileURI != "https://example.com/doc.pdf" || fd.MimeType != "application/pdf" {
+		t.Errorf("fileData = %+v", parts[1].FileData)
+	}
+	if parts[2].InlineData == nil || parts[2].InlineData.MimeType != "application/pdf" {
+		t.Errorf("inlineData = %+v", parts[2].InlineData)
+	}
+}
+
+func TestAudioMapsToInlineData(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:    core.RoleUser,
+		Content: "transcribe",
+		Audio:   []core.AudioData{{Format: "mp3", Data: []byte{1, 2}}},
+	}}}
+	parts := c.buildPayload(p)["contents"].([]gemContent)[0].Parts
+	if len(parts) != 2 || parts[1].InlineData == nil || parts[1].InlineData.MimeType != "audio/mp3" {
+		t.Fatalf("parts = %+v", parts)
+	}
+}
+
+func TestBuildPayloadToolConfig(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	base := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		ToolDefs: []core.ToolDef{{Name: "f"}},
+	}
+
+	p := base
+	p.ToolChoice = core.ToolChoice{Mode: "tool", Name: "f"}
+	fcc := c.buildPayload(p)["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)
+	if fcc["mode"] != "ANY" || fcc["allowedFunctionNames"].([]string)[0] != "f" {
+		t.Errorf("functionCallingConfig = %v", fcc)
+	}
+
+	p.ToolChoice = core.ToolChoice{Mode: "none"}
+	fcc = c.buildPayload(p)["toolConfig"].(map[string]any)["functionCallingConfig"].(map[string]any)
+	if fcc["mode"] != "NONE" {
+		t.Errorf("mode = %v", fcc["mode"])
+	}
+
+	if _, ok := c.buildPayload(base)["toolConfig"]; ok {
+		t.Error("zero ToolChoice should not emit toolConfig")
+	}
+}
+
+func TestToGeminiSchemaEnum(t *testing.T) {
+	out := toGeminiSchema(map[string]any{"type": "string", "enum": []any{"celsius", "fahrenheit"}})
+	if enum, ok := out["enum"].([]any); !ok || len(enum) != 2 {
+		t.Errorf("enum = %v", out["enum"])
+	}
+}
+
+func TestToGeminiSchemaConstraintSubset(t *testing.T) {
+	out := toGeminiSchema(map[string]any{
+		"type": "string", "minimum": 1.0, "pattern": "^x$", "minLength": 2,
+	})
+	if out["minimum"] != 1.0 {
+		t.Errorf("minimum dropped: %v", out)
+	}
+	if _, ok := out["pattern"]; ok {
+		t.Error("unsupported pattern keyword should be dropped for Gemini")
+	}
+	if _, ok := out["minLength"]; ok {
+		t.Error("unsupported minLength keyword should be dropped for Gemini")
+	}
+}
+
+// TestToGeminiSchemaFieldByField feeds a realistic reflected schema through
+// the converter and asserts every supported key survives with Gemini's
+// casing.
+func TestToGeminiSchemaFieldByField(t *testing.T) {
+	in := map[string]any{
+		"type":     "object",
+		"required": []string{"unit", "when"},
+		"properties": map[string]any{
+			"unit": map[string]any{
+				"type":        "string",
+				"description": "temperature unit",
+				"enum":        []any{"celsius", "fahrenheit"},
+			},
+			"when": map[string]any{
+				"type":   "string",
+				"format": "date-time",
+			},
+			"note": map[string]any{
+				"type":     "string",
+				"nullable": true,
+			},
+			"days": map[string]any{
+				"type":     "array",
+				"items":    map[string]any{"type": "integer"},
+				"maxItems": 7,
+			},
+		},
+	}
+	out := toGeminiSchema(in)
+	if out["type"] != "OBJECT" {
+		t.Errorf("type = %v", out["type"])
+	}
+	if req, ok := out["required"].([]string); !ok || len(req) != 2 {
+		t.Errorf("required = %v", out["required"])
+	}
+	props := out["properties"].(map[string]any)
+
+	unit := props["unit"].(map[string]any)
+	if unit["type"] != "STRING" || unit["description"] != "temperature unit" {
+		t.Errorf("unit = %v", unit)
+	}
+	if enum, ok := unit["enum"].([]any); !ok || len(enum) != 2 {
+		t.Errorf("unit enum = %v", unit["enum"])
+	}
+
+	when := props["when"].(map[string]any)
+	if when["format"] != "date-time" {
+		t.Errorf("when = %v", when)
+	}
+
+	note := props["note"].(map[string]any)
+	if note["nullable"] != true {
+		t.Errorf("note = %v", note)
+	}
+
+	days := props["days"].(map[string]any)
+	if days["type"] != "ARRAY" || days["maxItems"] != 7 {
+		t.Errorf("days = %v", days)
+	}
+	if days["items"].(map[string]any)["type"] != "INTEGER" {
+		t.Errorf("items = %v", days["items"])
+	}
+}
+
+func TestToGeminiSchemaNestedArrays(t *testing.T) {
+	in := map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"sku": map[string]any{"type": "string"},
+			},
+			"required": []string{"sku"},
+		},
+	}
+	out := toGeminiSchema(in)
+	if out["type"] != "ARRAY" {
+		t.Fatalf("out = %v", out)
+	}
+	items := out["items"].(map[string]any)
+	if items["type"] != "OBJECT" {
+		t.Errorf("items = %v", items)
+	}
+	if items["properties"].(map[string]any)["sku"].(map[string]any)["type"] != "STRING" {
+		t.Errorf("nested conversion broken: %v", items)
+	}
+	if req, ok := items["required"].([]string); !ok || req[0] != "sku" {
+		t.Errorf("required = %v", items["required"])
+	}
+}
+
+func TestToGeminiSchemaMapFallback(t *testing.T) {
+	out := toGeminiSchema(map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"type": "integer"},
+	})
+	if _, ok := out["additionalProperties"]; ok {
+		t.Error("Gemini dialect has no additionalProperties; constraint should be dropped")
+	}
+	if d, _ := out["description"].(string); d == "" {
+		t.Errorf("expected a description hint, got %v", out)
+	}
+}
+
+func TestToolParameterDescriptionsSurvive(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		ToolDefs: []core.ToolDef{{
+			Name:        "search",
+			Description: "Searches things",
+			Parameters: []core.ToolParameter{
+				{Name: "q", Description: "full-text query", Required: true, Schema: map[string]any{"type": "string"}},
+			},
+		}},
+	}
+	decls := c.buildPayload(p)["tools"].([]map[string]any)[0]["functionDeclarations"].([]map[string]any)
+	params := decls[0]["parameters"].(map[string]any)
+	q := params["properties"].(map[string]any)["q"].(map[string]any)
+	if q["description"] != "full-text query" {
+		t.Errorf("q = %v", q)
+	}
+}
+
+func TestResponseSchemaDescriptionsReachGemini(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		OutputSchema: `{"type":"object","properties":{
+			"summary":{"type":"string","description":"one-line summary","title":"Summary"}
+		}}`,
+	}
+	gc := c.buildPayload(p)["generationConfig"].(map[string]any)
+	rs := gc["responseSchema"].(map[string]any)
+	summary := rs["properties"].(map[string]any)["summary"].(map[string]any)
+	if summary["description"] != "one-line summary" || summary["title"] != "Summary" {
+		t.Errorf("summary = %v", summary)
+	}
+}
+
+// TestAPIKeyNeverInURL guards the secrets-hygiene rule: the key travels in
+// the x-goog-api-key header, so transport errors (which embed the URL)
+// cannot leak it.
+func TestAPIKeyNeverInURL(t *testing.T) {
+	var gotURL, gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotHeader = r.Header.Get("x-goog-api-key")
+		io.WriteString(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}],"usageMetadata":{"totalTokenCount":2}}`)
+	}))
+	defer srv.Close()
+
+	c := New([]string{"super-secret-key"}, srv.URL, slog.Default())
+	if _, err := c.Call(context.Background(), core.CallParams{
+		Model:    "gemini-2.0-flash",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(gotURL, "super-secret-key") {
+		t.Errorf("API key leaked into the URL: %s", gotURL)
+	}
+	if gotHeader != "super-secret-key" {
+		t.Errorf("x-goog-api-key = %q", gotHeader)
+	}
+
+	// A transport failure's error text must not contain the key either.
+	dead := New([]string{"super-secret-key"}, "http://127.0.0.1:1", slog.Default())
+	err := dead.Ping(context.Background(), "gemini-2.0-flash")
+	if err == nil {
+		t.Fatal("expected transport failure")
+	}
+	if strings.Contains(err.Error(), "super-secret-key") {
+		t.Errorf("API key leaked into error: %v", err)
+	}
+}
+
+func TestPingAppliesCustomHeaders(t *testing.T) {
+	var gotGateway string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotGateway = r.Header.Get("X-Api-Gateway-Key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := New([]string{"k"}, srv.URL, slog.Default())
+	c.SetHeaders(map[string]string{"X-Api-Gateway-Key": "gw"})
+	if err := c.Ping(context.Background(), "gemini-2.0-flash"); err != nil {
+		t.Fatal(err)
+	}
+	if gotGateway != "gw" {
+		t.Errorf("Ping did not apply custom headers: %q", gotGateway)
+	}
+}
+
+func TestCountTokensIncludesSystemAndTools(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotBody)
+		io.WriteString(w, `{"totalTokens": 99}`)
+	}))
+	defer srv.Close()
+
+	c := New([]string{"k"}, srv.URL, slog.Default())
+	n, err := c.CountTokens(context.Background(), core.CallParams{
+		Model:    "gemini-2.0-flash",
+		System:   "be terse",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		ToolDefs: []core.ToolDef{{Name: "echo", Description: "d", Parameters: []core.ToolParameter{
+			{Name: "text", Required: true, Schema: map[string]any{"type": "string"}},
+		}}},
+	})
+	if err != nil || n != 99 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if _, ok := gotBody["systemInstruction"]; !ok {
+		t.Error("countTokens payload dropped the system instruction")
+	}
+	if _, ok := gotBody["tools"]; !ok {
+		t.Error("countTokens payload dropped the tool definitions")
+	}
+}
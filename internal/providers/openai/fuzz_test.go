@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core/convtest"
+)
+
+// FuzzMapMessages asserts the serializer contract documented on
+// core.Message holds for arbitrary valid conversations: legal roles, tool
+// messages always carrying call IDs that pair with an earlier assistant
+// tool call, and pure (repeatable) serialization.
+func FuzzMapMessages(f *testing.F) {
+	for _, seed := range []int64{0, 1, 2, 42, 1234} {
+		f.Add(seed, 6)
+	}
+	f.Fuzz(func(t *testing.T, seed int64, n int) {
+		if n < 0 {
+			n = -n
+		}
+		c := New([]string{"k"}, "", slog.Default())
+		msgs := convtest.Conversation(rand.New(rand.NewSource(seed)), n%10)
+		out := c.mapMessages(msgs)
+
+		issued := map[string]bool{}
+		for i, m := range out {
+			switch m.Role {
+			case "system", "user", "assistant":
+			case "tool":
+				if m.ToolCallID == "" {
+					t.Fatalf("tool message %d missing tool_call_id", i)
+				}
+				if !issued[m.ToolCallID] {
+					t.Fatalf("tool message %d references unknown call %q", i, m.ToolCallID)
+				}
+			default:
+				t.Fatalf("message %d has illegal role %q", i, m.Role)
+			}
+			for _, tc := range m.ToolCalls {
+				if tc.ID == "" || tc.Function.Name == "" {
+					t.Fatalf("message %d has incomplete tool call %+v", i, tc)
+				}
+				issued[tc.ID] = true
+			}
+		}
+
+		if again := c.mapMessages(msgs); !reflect.DeepEqual(out, again) {
+			t.Fatal("serialization is not pure: two passes differ")
+		}
+	})
+}
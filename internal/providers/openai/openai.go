@@ -0,0 +1,624 @@
+// Package openai implements the router's client for the OpenAI chat
+// completions API and compatible endpoints.
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/retry"
+)
+
+const defaultBaseURL = "https://api.openai.com/v1"
+
+// Client talks to one OpenAI-compatible endpoint.
+type Client struct {
+	keys     *core.KeyRing
+	baseURL  string
+	http     *http.Client
+	logger   *slog.Logger
+	verbose  bool
+	recorder core.PayloadRecorder
+	headers  map[string]string
+	retryCfg retry.Config
+}
+
+// SetRetryConfig overrides the default retry policy for this client.
+func (c *Client) SetRetryConfig(cfg retry.Config) { c.retryCfg = cfg }
+
+// SetHeaders installs extra HTTP headers applied to every outgoing
+// request. Header values are treated as secrets and never logged.
+func (c *Client) SetHeaders(h map[string]string) { c.headers = h }
+
+// applyHeaders stamps the configured extra headers onto req.
+func (c *Client) applyHeaders(req *http.Request) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// SetPayloadRecorder installs a recorder receiving redacted request and
+// response payloads for every call.
+func (c *Client) SetPayloadRecorder(fn core.PayloadRecorder) { c.recorder = fn }
+
+// record hands redacted payloads to the recorder, scrubbing the API key
+// wherever it might appear.
+func (c *Client) record(req, resp []byte, status int) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder("openai", c.redact(req), c.redact(resp), status)
+}
+
+func (c *Client) redact(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	for _, key := range c.keys.All() {
+		if key != "" {
+			b = bytes.ReplaceAll(b, []byte(key), []byte("[REDACTED]"))
+		}
+	}
+	return b
+}
+
+// New builds a Client rotating round-robin over the given API keys.
+// baseURL may be empty to use the public API.
+func New(keys []string, baseURL string, logger *slog.Logger) *Client {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		keys:     core.NewKeyRing(keys...),
+		baseURL:  baseURL,
+		http:     &http.Client{},
+		logger:   logger,
+		verbose:  os.Getenv("LLM_VERBOSE_MESSAGES") == "1",
+		retryCfg: retry.Default,
+	}
+}
+
+type oaiMessage struct {
+	Role       string        `json:"role"`
+	Content    any           `json:"content,omitempty"`
+	ToolCalls  []oaiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+	Name       string        `json:"name,omitempty"`
+}
+
+type oaiToolCall struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Function oaiToolCallFunc `json:"function"`
+}
+
+type oaiToolCallFunc struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type oaiTool struct {
+	Type     string      `json:"type"`
+	Function oaiFunction `json:"function"`
+}
+
+type oaiFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+	Strict      bool           `json:"strict,omitempty"`
+}
+
+func (f oaiFunction) asTool() oaiTool { return oaiTool{Type: "function", Function: f} }
+
+// Warm implements core.WarmableClient by issuing a cheap authenticated GET
+// so the TLS handshake and connection setup happen before the first real
+// call. Non-2xx statuses are not errors here; only transport failures are.
+func (c *Client) Warm(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", nil)
+	if err != nil {
+		return err
+	}
+	key, _ := c.keys.Pick()
+	req.Header.Set("Authorization", "Bearer "+key)
+	c.applyHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// buildPayload assembles the chat completions request body.
+func (c *Client) buildPayload(p core.CallParams) map[string]any {
+	msgs := c.mapMessages(p.Messages)
+	if p.System != "" {
+		// Merge rather than duplicate: skip the prepend when the caller
+		// already leads with an identical system message.
+		if len(msgs) == 0 || msgs[0].Role != "system" || msgs[0].Content != p.System {
+			msgs = append([]oaiMessage{{Role: "system", Content: p.System}}, msgs...)
+		}
+	}
+	payload := map[string]any{
+		"model":    p.Model,
+		"messages": msgs,
+	}
+	if p.Temperature != nil {
+		payload["temperature"] = *p.Temperature
+	}
+	if p.TopP != nil {
+		payload["top_p"] = *p.TopP
+	}
+	if p.MaxTokens != nil {
+		payload["max_tokens"] = *p.MaxTokens
+	}
+	if p.Seed != nil {
+		payload["seed"] = *p.Seed
+	}
+	if p.CandidateCount > 1 {
+		payload["n"] = p.CandidateCount
+	}
+	if len(p.ToolDefs) > 0 {
+		payload["tools"] = mapTools(p.ToolDefs, p.StrictTools)
+		switch p.ToolChoice.Mode {
+		case "auto", "none", "required":
+			payload["tool_choice"] = p.ToolChoice.Mode
+		case "tool":
+			payload["tool_choice"] = map[string]any{
+				"type":     "function",
+				"function": map[string]any{"name": p.ToolChoice.Name},
+			}
+		}
+	}
+	switch {
+	case p.OutputSchema != "" && p.StrictJSONSchema:
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(p.OutputSchema), &schema); err == nil {
+			payload["response_format"] = map[string]any{
+				"type": "json_schema",
+				"json_schema": map[string]any{
+					"name":   "response",
+					"strict": true,
+					"schema": strictifySchema(schema),
+				},
+			}
+			break
+		}
+		fallthrough
+	case p.OutputSchema != "" || p.ForceJSON:
+		payload["response_format"] = map[string]any{"type": "json_object"}
+	}
+	if len(p.Extra) > 0 {
+		core.MergeExtra(payload, p.Extra, c.logger, "openai")
+	}
+	return payload
+}
+
+// maxInlineBytes is the limit for data-URL image payloads.
+const maxInlineBytes = 20 << 20
+
+// validateAttachments rejects oversized inline images before sending.
+func validateAttachments(p core.CallParams) error {
+	for _, m := range p.Messages {
+		for _, img := range m.ImageData {
+			if len(img.Data) > maxInlineBytes {
+				return fmt.Errorf("%w: openai inline image of %d bytes (limit %d)",
+					core.ErrAttachmentTooLarge, len(img.Data), maxInlineBytes)
+			}
+		}
+		for _, f := range m.Files {
+			// The chat completions API takes file content inline; there is
+			// no URI form, and dropping the attachment would be worse than
+			// failing.
+			if f.URI != "" {
+				return fmt.Errorf("%w: openai has no URI file inputs, supply bytes", core.ErrUnsupportedAttachment)
+			}
+			if len(f.Data) > maxInlineBytes {
+				return fmt.Errorf("%w: openai inline file of %d bytes (limit %d)",
+					core.ErrAttachmentTooLarge, len(f.Data), maxInlineBytes)
+			}
+		}
+	}
+	return nil
+}
+
+// Ping implements core.Pinger with a single GET /models/{id} — the
+// cheapest authenticated call that also confirms the model exists. It never
+// retries.
+func (c *Client) Ping(ctx context.Context, model string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models/"+model, nil)
+	if err != nil {
+		return err
+	}
+	key, _ := c.keys.Pick()
+	req.Header.Set("Authorization", "Bearer "+key)
+	c.applyHeaders(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("openai: ping: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: openai status %d", core.ErrAuthFailed, resp.StatusCode)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: openai model %q", core.ErrModelNotFound, model)
+	default:
+		return fmt.Errorf("openai: ping status %d: %s", resp.StatusCode, truncate(body, 256))
+	}
+}
+
+// Call implements core.RawClient.
+func (c *Client) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	if err := validateAttachments(p); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(c.buildPayload(p))
+	if err != nil {
+		return nil, err
+	}
+	logger := c.logger
+	if p.RequestID != "" {
+		logger = logger.With("request_id", p.RequestID)
+	}
+	if c.verbose {
+		logger.Debug("openai request payload", "body", string(body))
+	}
+
+	var resp *core.RawResponse
+	err = retry.Do(ctx, c.retryCfg, logger, func() error {
+		var callErr error
+		resp, callErr = c.doCall(ctx, body, p.RequestID)
+		return callErr
+	})
+	return resp, err
+}
+
+func (c *Client) doCall(ctx context.Context, body []byte, requestID string) (*core.RawResponse, error) {
+	start := time.Now()
+	var ttfb time.Duration
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { ttfb = time.Since(start) },
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	key, keyIndex := c.keys.Pick()
+	if c.keys.Len() > 1 {
+		c.logger.Debug("using api key", "key_index", keyIndex)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+key)
+	if requestID != "" {
+		req.Header.Set("X-Request-Id", requestID)
+	}
+	c.applyHeaders(req)
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		c.record(body, nil, 0)
+		return nil, retry.Transient(err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		c.record(body, nil, httpResp.StatusCode)
+		return nil, retry.Transient(err)
+	}
+	c.record(body, respBody, httpResp.StatusCode)
+	if httpResp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: openai status 404: %s", core.ErrModelNotFound, truncate(respBody, 256))
+	}
+	if httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: openai status %d", core.ErrAuthFailed, httpResp.StatusCode)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("openai: status %d: %s", httpResp.StatusCode, truncate(respBody, 512))
+		if httpResp.StatusCode == http.StatusTooManyRequests {
+			c.keys.MarkRateLimited(keyIndex)
+			c.logger.Warn("api key rate limited, cooling down", "key_index", keyIndex)
+		}
+		if httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500 {
+			return nil, retry.Transient(err)
+		}
+		return nil, err
+	}
+	resp, err := parseResponse(respBody)
+	if err != nil {
+		return nil, err
+	}
+	resp.Timing = core.Timing{TTFB: ttfb, Total: time.Since(start)}
+	return resp, nil
+}
+
+func parseResponse(body []byte) (*core.RawResponse, error) {
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content   string        `json:"content"`
+				ToolCalls []oaiToolCall `json:"tool_calls"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("openai: parsing response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("openai: response contained no choices")
+	}
+	out := &core.RawResponse{
+		Content:      parsed.Choices[0].Message.Content,
+		FinishReason: mapFinishReason(parsed.Choices[0].FinishReason),
+		Usage: core.Usage{
+			PromptTokens:     parsed.Usage.PromptTokens,
+			CompletionTokens: parsed.Usage.CompletionTokens,
+			TotalTokens:      parsed.Usage.TotalTokens,
+		},
+	}
+	for _, tc := range parsed.Choices[0].Message.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, core.ToolCall{
+			CallID: tc.ID,
+			Name:   tc.Function.Name,
+			Args:   json.RawMessage(tc.Function.Arguments),
+		})
+	}
+	for _, ch := range parsed.Choices {
+		out.Candidates = append(out.Candidates, core.Candidate{
+			Content:      ch.Message.Content,
+			FinishReason: mapFinishReason(ch.FinishReason),
+		})
+	}
+	return out, nil
+}
+
+// mapMessages converts canonical messages to the chat completions shape.
+func (c *Client) mapMessages(msgs []core.Message) []oaiMessage {
+	var out []oaiMessage
+	for _, m := range msgs {
+		switch {
+		case len(m.ToolResults) > 0:
+			for _, tr := range m.ToolResults {
+				content, _ := json.Marshal(tr.Content)
+				out = append(out, oaiMessage{
+					Role:       "tool",
+					Content:    string(content),
+					ToolCallID: tr.CallID,
+					Name:       tr.Name,
+				})
+			}
+		case len(m.ToolCalls) > 0:
+			msg := oaiMessage{Role: string(m.Role)}
+			if m.Content != "" {
+				msg.Content = m.Content
+			}
+			for _, tc := range m.ToolCalls {
+				msg.ToolCalls = append(msg.ToolCalls, oaiToolCall{
+					ID:   tc.CallID,
+					Type: "function",
+					Function: oaiToolCallFunc{
+						Name:      tc.Name,
+						Arguments: string(tc.Args),
+					},
+				})
+			}
+			out = append(out, msg)
+		case len(m.Images) > 0 || len(m.ImageData) > 0 || len(m.Files) > 0 || len(m.Audio) > 0:
+			parts := []map[string]any{{"type": "text", "text": m.Content}}
+			for _, url := range m.Images {
+				parts = append(parts, map[string]any{
+					"type":      "image_url",
+					"image_url": map[string]any{"url": url},
+				})
+			}
+			for _, img := range m.ImageData {
+				parts = append(parts, map[string]any{
+					"type":      "image_url",
+					"image_url": map[string]any{"url": img.DataURI()},
+				})
+			}
+			for _, f := range m.Files {
+				parts = append(parts, map[string]any{
+					"type": "file",
+					"file": map[string]any{
+						"filename":  f.Filename(),
+						"file_data": f.DataURI(),
+					},
+				})
+			}
+			for _, au := range m.Audio {
+				parts = append(parts, map[string]any{
+					"type": "input_audio",
+					"input_audio": map[string]any{
+						"data":   base64.StdEncoding.EncodeToString(au.Data),
+						"format": au.Format,
+					},
+				})
+			}
+			out = append(out, oaiMessage{Role: string(m.Role), Content: parts})
+		default:
+			out = append(out, oaiMessage{Role: string(m.Role), Content: m.Content})
+		}
+	}
+	return out
+}
+
+func mapTools(defs []core.ToolDef, strict bool) []oaiTool {
+	out := make([]oaiTool, 0, len(defs))
+	for _, d := range defs {
+		fn := oaiFunction{
+			Name:        d.Name,
+			Description: d.Description,
+			Parameters:  core.GenerateJSONSchemaFromToolDef(d),
+		}
+		if strict {
+			fn.Parameters = strictifySchema(fn.Parameters)
+			fn.Strict = true
+		}
+		out = append(out, fn.asTool())
+	}
+	return out
+}
+
+// strictUnsupportedKeywords are schema keywords OpenAI's strict validator
+// rejects; they are stripped (the softer validation hints they express are
+// still enforced locally by the router where applicable).
+var strictUnsupportedKeywords = []string{
+	"minLength", "maxLength", "pattern",
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minItems", "maxItems",
+	"examples", "example", "nullable",
+}
+
+// strictAllowedFormats is the format subset strict mode accepts; others are
+// dropped rather than risking a 400.
+var strictAllowedFormats = map[string]bool{
+	"date-time": true, "time": true, "date": true, "duration": true,
+	"email": true, "hostname": true, "ipv4": true, "ipv6": true, "uuid": true,
+}
+
+// strictifySchema returns a copy of schema transformed to satisfy OpenAI's
+// strict-mode constraints, shared by the function-calling and
+// response_format paths: every object declares additionalProperties false
+// and lists all properties as required, previously-optional and nullable
+// properties get a null type union so the model can still express absence,
+// and keywords the strict validator rejects are stripped. The input —
+// possibly a shared cached fragment — is never mutated.
+func strictifySchema(schema map[string]any) map[string]any {
+	out := deepCopyMap(schema)
+	strictifyNode(out)
+	return out
+}
+
+func strictifyNode(node map[string]any) {
+	nullable, _ := node["nullable"].(bool)
+	for _, k := range strictUnsupportedKeywords {
+		delete(node, k)
+	}
+	if f, ok := node["format"].(string); ok && !strictAllowedFormats[f] {
+		delete(node, "format")
+	}
+	if nullable {
+		nullUnionType(node)
+	}
+
+	if t, _ := node["type"].(string); t == "object" {
+		wasRequired := map[string]bool{}
+		switch req := node["required"].(type) {
+		case []string:
+			for _, n := range req {
+				wasRequired[n] = true
+			}
+		case []any:
+			for _, n := range req {
+				if s, ok := n.(string); ok {
+					wasRequired[s] = true
+				}
+			}
+		}
+		node["additionalProperties"] = false
+		if props, ok := node["properties"].(map[string]any); ok {
+			required := make([]string, 0, len(props))
+			for name, sub := range props {
+				required = append(required, name)
+				if subMap, ok := sub.(map[string]any); ok && !wasRequired[name] {
+					nullUnionType(subMap)
+				}
+			}
+			sort.Strings(required)
+			node["required"] = required
+		}
+	}
+
+	for _, key := range []string{"properties", "$defs"} {
+		if m, ok := node[key].(map[string]any); ok {
+			for _, sub := range m {
+				if subMap, ok := sub.(map[string]any); ok {
+					strictifyNode(subMap)
+				}
+			}
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		strictifyNode(items)
+	}
+	if ap, ok := node["additionalProperties"].(map[string]any); ok {
+		strictifyNode(ap)
+	}
+}
+
+// nullUnionType widens a plain type to [T, "null"], leaving existing unions
+// alone.
+func nullUnionType(node map[string]any) {
+	if st, ok := node["type"].(string); ok {
+		node["type"] = []any{st, "null"}
+	}
+}
+
+func deepCopyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		switch vv := v.(type) {
+		case map[string]any:
+			out[k] = deepCopyMap(vv)
+		case []any:
+			cp := make([]any, len(vv))
+			for i, e := range vv {
+				if em, ok := e.(map[string]any); ok {
+					cp[i] = deepCopyMap(em)
+				} else {
+					cp[i] = e
+				}
+			}
+			out[k] = cp
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// mapFinishReason normalizes OpenAI's finish_reason values.
+func mapFinishReason(s string) core.FinishReason {
+	switch s {
+	case "stop":
+		return core.FinishStop
+	case "length":
+		return core.FinishLength
+	case "tool_calls", "function_call":
+		return core.FinishToolCalls
+	case "content_filter":
+		return core.FinishContentFilter
+	default:
+		return core.FinishUnknown
+	}
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) <= n {
+		return string(b)
+	}
+	return string(b[:n]) + "..."
+}
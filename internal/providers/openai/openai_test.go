@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestParseResponseFinishReason(t *testing.T) {
+	body := []byte(`{
+		"choices": [{"message": {"content": "truncated outpu"}, "finish_reason": "length"}],
+		"usage": {"prompt_tokens": 5, "completion_tokens": 100, "total_tokens": 105}
+	}`)
+	resp, err := parseResponse(body)
+	if err != nil {
+		t.Fatalf("parseResponse: %v", err)
+	}
+	if resp.FinishReason != core.FinishLength {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, core.FinishLength)
+	}
+}
+
+func TestMapFinishReason(t *testing.T) {
+	tests := map[string]core.FinishReason{
+		"stop":           core.FinishStop,
+		"length":         core.FinishLength,
+		"tool_calls":     core.FinishToolCalls,
+		"function_call":  core.FinishToolCalls,
+		"content_filter": core.FinishContentFilter,
+		"weird":          core.FinishUnknown,
+		"":               core.FinishUnknown,
+	}
+	for in, want := range tests {
+		if got := mapFinishReason(in); got != want {
+			t.Errorf("mapFinishReason(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
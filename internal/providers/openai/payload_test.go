@@ -0,0 +1,408 @@
+package openai
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestBuildPayloadJSONMode(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	base := core.CallParams{
+		Model:    "gpt-4o",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+	}
+	tests := []struct {
+		name       string
+		schema     string
+		forceJSON  bool
+		wantFormat bool
+	}{
+		{"plain text", "", false, false},
+		{"schema only", `{"type":"object"}`, false, true},
+		{"force json only", "", true, true},
+		{"schema and force json", `{"type":"object"}`, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			p.OutputSchema = tt.schema
+			p.ForceJSON = tt.forceJSON
+			payload := c.buildPayload(p)
+			rf, ok := payload["response_format"]
+			if ok != tt.wantFormat {
+				t.Fatalf("response_format present = %v, want %v", ok, tt.wantFormat)
+			}
+			if ok {
+				if m := rf.(map[string]any); m["type"] != "json_object" {
+					t.Errorf("response_format = %v", rf)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPayloadSystemField(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	sys := "you are terse"
+
+	// System alone becomes a leading system message.
+	p := core.CallParams{System: sys, Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}}}
+	msgs := c.buildPayload(p)["messages"].([]oaiMessage)
+	if len(msgs) != 2 || msgs[0].Role != "system" || msgs[0].Content != sys {
+		t.Errorf("messages = %+v", msgs)
+	}
+
+	// An identical explicit system message is not duplicated.
+	p.Messages = []core.Message{{Role: core.RoleSystem, Content: sys}, {Role: core.RoleUser, Content: "hi"}}
+	msgs = c.buildPayload(p)["messages"].([]oaiMessage)
+	if len(msgs) != 2 {
+		t.Errorf("duplicate system prompt: %+v", msgs)
+	}
+
+	// A different explicit system message is kept alongside.
+	p.Messages = []core.Message{{Role: core.RoleSystem, Content: "other"}, {Role: core.RoleUser, Content: "hi"}}
+	msgs = c.buildPayload(p)["messages"].([]oaiMessage)
+	if len(msgs) != 3 || msgs[0].Content != sys || msgs[1].Content != "other" {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestBuildPayloadSeed(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}}}
+
+	if _, ok := c.buildPayload(p)["seed"]; ok {
+		t.Error("seed should be omitted when unset")
+	}
+	zero := int64(0)
+	p.Seed = &zero
+	if got, ok := c.buildPayload(p)["seed"]; !ok || got != int64(0) {
+		t.Errorf("seed = %v (%v), want explicit 0", got, ok)
+	}
+}
+
+func TestBuildPayloadIgnoresTopK(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	topK := 40
+	p := core.CallParams{Model: "m", Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}}, TopK: &topK}
+	payload := c.buildPayload(p)
+	for k := range payload {
+		if k == "top_k" {
+			t.Error("OpenAI payload must not contain top_k")
+		}
+	}
+}
+
+func TestBuildPayloadExtraOptions(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		Extra: map[string]any{
+			"logit_bias": map[string]any{"50256": -100},
+			"messages":   "overwrite attempt",
+		},
+	}
+	payload := c.buildPayload(p)
+	if _, ok := payload["logit_bias"]; !ok {
+		t.Error("unmanaged extra key should be merged in")
+	}
+	if _, isString := payload["messages"].(string); isString {
+		t.Error("extra option must not overwrite the router-managed messages field")
+	}
+}
+
+func TestImageDataMapsToDataURL(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:      core.RoleUser,
+		Content:   "what is this?",
+		ImageData: []core.ImageData{{MIME: "image/jpeg", Data: []byte{1, 2, 3}}},
+	}}}
+	msgs := c.buildPayload(p)["messages"].([]oaiMessage)
+	parts := msgs[0].Content.([]map[string]any)
+	if len(parts) != 2 {
+		t.Fatalf("parts = %+v", parts)
+	}
+	url := parts[1]["image_url"].(map[string]any)["url"].(string)
+	if want := "data:image/jpeg;base64,AQID"; url != want {
+		t.Errorf("url = %q, want %q", url, want)
+	}
+}
+
+func TestFileAttachments(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:    core.RoleUser,
+		Content: "summarize",
+		Files:   []core.FileAttachment{{MIME: "application/pdf", Data: []byte("%PDF-")}},
+	}}}
+	msgs := c.buildPayload(p)["messages"].([]oaiMessage)
+	parts := msgs[0].Content.([]map[string]any)
+	if len(parts) != 2 || parts[1]["type"] != "file" {
+		t.Fatalf("parts = %+v", parts)
+	}
+	// The API rejects file parts without a filename; one is synthesized
+	// from the MIME type when the caller didn't name the attachment.
+	file := parts[1]["file"].(map[string]any)
+	if file["filename"] != "attachment.pdf" {
+		t.Errorf("filename = %v", file["filename"])
+	}
+
+	named := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:  core.RoleUser,
+		Files: []core.FileAttachment{{MIME: "application/pdf", Name: "report.pdf", Data: []byte("%PDF-")}},
+	}}}
+	file = c.buildPayload(named)["messages"].([]oaiMessage)[0].Content.([]map[string]any)[1]["file"].(map[string]any)
+	if file["filename"] != "report.pdf" {
+		t.Errorf("filename = %v, want caller-supplied name", file["filename"])
+	}
+
+	// URI-form files have no chat-completions representation: typed error.
+	err := validateAttachments(core.CallParams{Messages: []core.Message{{
+		Files: []core.FileAttachment{{MIME: "application/pdf", URI: "https://example.com/x.pdf"}},
+	}}})
+	if !errors.Is(err, core.ErrUnsupportedAttachment) {
+		t.Errorf("err = %v, want ErrUnsupportedAttachment", err)
+	}
+}
+
+func TestAudioMapsToInputAudio(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{Model: "m", Messages: []core.Message{{
+		Role:    core.RoleUser,
+		Content: "transcribe",
+		Audio:   []core.AudioData{{Format: "wav", Data: []byte{1, 2}}},
+	}}}
+	parts := c.buildPayload(p)["messages"].([]oaiMessage)[0].Content.([]map[string]any)
+	if len(parts) != 2 || parts[1]["type"] != "input_audio" {
+		t.Fatalf("parts = %+v", parts)
+	}
+	ia := parts[1]["input_audio"].(map[string]any)
+	if ia["format"] != "wav" || ia["data"] == "" {
+		t.Errorf("input_audio = %+v", ia)
+	}
+}
+
+func TestParseResponseMultipleChoices(t *testing.T) {
+	body := []byte(`{
+		"choices": [
+			{"message": {"content": "first"}, "finish_reason": "stop"},
+			{"message": {"content": "second"}, "finish_reason": "length"}
+		],
+		"usage": {"prompt_tokens": 5, "completion_tokens": 20, "total_tokens": 25}
+	}`)
+	resp, err := parseResponse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "first" {
+		t.Errorf("Content = %q, want first candidate", resp.Content)
+	}
+	if len(resp.Candidates) != 2 || resp.Candidates[1].Content != "second" || resp.Candidates[1].FinishReason != core.FinishLength {
+		t.Errorf("Candidates = %+v", resp.Candidates)
+	}
+	if resp.Usage.CompletionTokens != 20 {
+		t.Errorf("usage should be the provider's combined count, got %d", resp.Usage.CompletionTokens)
+	}
+}
+
+func TestBuildPayloadToolChoice(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	base := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		ToolDefs: []core.ToolDef{{Name: "f"}},
+	}
+
+	p := base
+	p.ToolChoice = core.ToolChoice{Mode: "required"}
+	if got := c.buildPayload(p)["tool_choice"]; got != "required" {
+		t.Errorf("tool_choice = %v", got)
+	}
+
+	p.ToolChoice = core.ToolChoice{Mode: "tool", Name: "f"}
+	tc := c.buildPayload(p)["tool_choice"].(map[string]any)
+	if tc["function"].(map[string]any)["name"] != "f" {
+		t.Errorf("tool_choice = %v", tc)
+	}
+
+	if _, ok := c.buildPayload(base)["tool_choice"]; ok {
+		t.Error("zero ToolChoice should leave the provider default")
+	}
+}
+
+func TestBuildPayloadJSONSchemaStrict(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	schema := `{"type":"object","properties":{"name":{"type":"string"},"tags":{"type":"array","items":{"type":"object","properties":{"v":{"type":"string"}}}}},"required":["name"]}`
+	p := core.CallParams{
+		Model:            "gpt-4o",
+		Messages:         []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		OutputSchema:     schema,
+		StrictJSONSchema: true,
+	}
+	rf := c.buildPayload(p)["response_format"].(map[string]any)
+	if rf["type"] != "json_schema" {
+		t.Fatalf("response_format = %v", rf)
+	}
+	js := rf["json_schema"].(map[string]any)
+	if js["strict"] != true || js["name"] == "" {
+		t.Errorf("json_schema = %v", js)
+	}
+	root := js["schema"].(map[string]any)
+	if root["additionalProperties"] != false {
+		t.Error("root must set additionalProperties false")
+	}
+	req := root["required"].([]string)
+	if len(req) != 2 { // all properties listed
+		t.Errorf("required = %v", req)
+	}
+	nested := root["properties"].(map[string]any)["tags"].(map[string]any)["items"].(map[string]any)
+	if nested["additionalProperties"] != false {
+		t.Error("nested objects must also be strictified")
+	}
+
+	// Without the capability flag the old json_object path is kept.
+	p.StrictJSONSchema = false
+	rf = c.buildPayload(p)["response_format"].(map[string]any)
+	if rf["type"] != "json_object" {
+		t.Errorf("fallback response_format = %v", rf)
+	}
+}
+
+func TestToolParameterDescriptionsSurvive(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	p := core.CallParams{
+		Model:    "m",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		ToolDefs: []core.ToolDef{{
+			Name:        "search",
+			Description: "Searches things",
+			Parameters: []core.ToolParameter{
+				{Name: "q", Description: "full-text query", Required: true, Schema: map[string]any{"type": "string"}},
+				{Name: "window", Description: "lookback window in days", Schema: map[string]any{"type": "integer"}},
+			},
+		}},
+	}
+	tools := c.buildPayload(p)["tools"].([]oaiTool)
+	props := tools[0].Function.Parameters["properties"].(map[string]any)
+	if props["q"].(map[string]any)["description"] != "full-text query" {
+		t.Errorf("q = %v", props["q"])
+	}
+	if props["window"].(map[string]any)["description"] != "lookback window in days" {
+		t.Errorf("window = %v", props["window"])
+	}
+}
+
+func TestMapToolsStrictMode(t *testing.T) {
+	defs := []core.ToolDef{{
+		Name: "search",
+		Parameters: []core.ToolParameter{
+			{Name: "q", Required: true, Schema: map[string]any{"type": "string"}},
+			{Name: "window", Required: false, Schema: map[string]any{"type": "integer"}},
+		},
+	}}
+
+	strictTools := mapTools(defs, true)
+	fn := strictTools[0].Function
+	if !fn.Strict {
+		t.Error("strict flag not set")
+	}
+	params := fn.Parameters
+	if params["additionalProperties"] != false {
+		t.Error("additionalProperties must be false in strict mode")
+	}
+	required := params["required"].([]string)
+	if len(required) != 2 {
+		t.Errorf("required = %v, want all properties listed", required)
+	}
+	window := params["properties"].(map[string]any)["window"].(map[string]any)
+	typ, ok := window["type"].([]any)
+	if !ok || typ[1] != "null" {
+		t.Errorf("optional field should be nullable: %v", window)
+	}
+	q := params["properties"].(map[string]any)["q"].(map[string]any)
+	if _, isSlice := q["type"].([]any); isSlice {
+		t.Errorf("required field should keep its plain type: %v", q)
+	}
+
+	// Non-strict mapping is untouched, and the original defs were not
+	// mutated by the strict pass.
+	plain := mapTools(defs, false)
+	if plain[0].Function.Strict {
+		t.Error("strict leaked into non-strict mapping")
+	}
+	if _, ok := plain[0].Function.Parameters["additionalProperties"]; ok {
+		t.Error("strict transform mutated the shared schema")
+	}
+}
+
+// TestStrictifySchemaStripsUnsafeKeywords pins the strict-mode hygiene for
+// the response_format path: optional fields become nullable unions and
+// keywords the strict validator rejects never reach the wire.
+func TestStrictifySchemaStripsUnsafeKeywords(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	schema := `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"note": {"type": "string", "nullable": true},
+			"count": {"type": "integer", "minimum": 1},
+			"when": {"type": "string", "format": "date-time"},
+			"blob": {"type": "string", "format": "not-a-real-format"}
+		},
+		"required": ["name"]
+	}`
+	p := core.CallParams{
+		Model:            "gpt-4o",
+		Messages:         []core.Message{{Role: core.RoleUser, Content: "hi"}},
+		OutputSchema:     schema,
+		StrictJSONSchema: true,
+	}
+	rf := c.buildPayload(p)["response_format"].(map[string]any)
+	root := rf["json_schema"].(map[string]any)["schema"].(map[string]any)
+	props := root["properties"].(map[string]any)
+
+	name := props["name"].(map[string]any)
+	for _, banned := range []string{"minLength", "pattern"} {
+		if _, ok := name[banned]; ok {
+			t.Errorf("%s survived into strict schema: %v", banned, name)
+		}
+	}
+	if _, isUnion := name["type"].([]any); isUnion {
+		t.Errorf("originally-required field should keep its plain type: %v", name)
+	}
+
+	note := props["note"].(map[string]any)
+	if _, ok := note["nullable"]; ok {
+		t.Errorf("nullable keyword survived: %v", note)
+	}
+	if typ, ok := note["type"].([]any); !ok || typ[1] != "null" {
+		t.Errorf("nullable/optional field should be a null union: %v", note)
+	}
+
+	count := props["count"].(map[string]any)
+	if _, ok := count["minimum"]; ok {
+		t.Errorf("minimum survived: %v", count)
+	}
+	if typ, ok := count["type"].([]any); !ok || typ[0] != "integer" {
+		t.Errorf("optional count should be null-unioned: %v", count)
+	}
+
+	if props["when"].(map[string]any)["format"] != "date-time" {
+		t.Errorf("supported format dropped: %v", props["when"])
+	}
+	if _, ok := props["blob"].(map[string]any)["format"]; ok {
+		t.Errorf("unsupported format survived: %v", props["blob"])
+	}
+
+	if req := root["required"].([]string); len(req) != 5 {
+		t.Errorf("required = %v, want all properties listed", req)
+	}
+	if root["additionalProperties"] != false {
+		t.Error("additionalProperties must be false")
+	}
+}
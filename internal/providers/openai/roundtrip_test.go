@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// TestMapMessagesRoundTrip asserts that a conversation resumed from
+// persisted messages (tool calls and results included) maps to exactly the
+// payload the tool loop's own messages produce, so saved conversations can
+// be fed back in without drift.
+func TestMapMessagesRoundTrip(t *testing.T) {
+	c := New([]string{"k"}, "", slog.Default())
+	conversation := []core.Message{
+		{Role: core.RoleSystem, Content: "be terse"},
+		{Role: core.RoleUser, Content: "weather?"},
+		{Role: core.RoleAssistant, ToolCalls: []core.ToolCall{
+			{CallID: "call_1", Name: "get_weather", Args: json.RawMessage(`{"location":"Paris"}`)},
+		}},
+		{Role: core.RoleTool, ToolResults: []core.ToolResult{
+			{CallID: "call_1", Name: "get_weather", Content: map[string]any{"forecast": "sunny"}},
+		}},
+		{Role: core.RoleAssistant, Content: "Sunny in Paris."},
+	}
+
+	first := c.mapMessages(conversation)
+
+	// Persist and reload the conversation (what callers do with
+	// Conversation.Messages), then map again.
+	reloaded := make([]core.Message, len(conversation))
+	copy(reloaded, conversation)
+	second := c.mapMessages(reloaded)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("round-tripped conversation mapped differently:\n%+v\nvs\n%+v", first, second)
+	}
+
+	if first[3].Role != "tool" || first[3].ToolCallID != "call_1" {
+		t.Errorf("tool result mapped to %+v", first[3])
+	}
+}
@@ -0,0 +1,44 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// TestCallTimingSeparatesHeadersFromBody delays headers and body differently
+// and asserts the breakdown reflects it: TTFB captures the pre-header delay,
+// Total additionally includes the body delay.
+func TestCallTimingSeparatesHeadersFromBody(t *testing.T) {
+	const headerDelay = 40 * time.Millisecond
+	const bodyDelay = 40 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(headerDelay)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(bodyDelay)
+		io.WriteString(w, `{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	c := New([]string{"k"}, srv.URL, slog.Default())
+	resp, err := c.Call(context.Background(), core.CallParams{
+		Model:    "gpt-4o",
+		Messages: []core.Message{{Role: core.RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if resp.Timing.TTFB < headerDelay {
+		t.Errorf("TTFB = %v, want >= %v", resp.Timing.TTFB, headerDelay)
+	}
+	if resp.Timing.Total < resp.Timing.TTFB+bodyDelay/2 {
+		t.Errorf("Total = %v should exceed TTFB (%v) by roughly the body delay", resp.Timing.Total, resp.Timing.TTFB)
+	}
+}
@@ -0,0 +1,116 @@
+// Package ratelimit implements the client-side token buckets the router
+// uses to stay under configured RPM and TPM limits instead of eating
+// provider 429s.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket refilled continuously at a per-minute rate. The
+// capacity equals one minute's allowance, so short bursts up to the limit
+// are permitted.
+type Bucket struct {
+	mu     sync.Mutex
+	tokens float64
+	limit  float64 // per minute; also the capacity
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewBucket builds a bucket allowing limit units per minute, starting full.
+func NewBucket(limit int) *Bucket {
+	b := &Bucket{tokens: float64(limit), limit: float64(limit), now: time.Now}
+	b.last = b.now()
+	return b
+}
+
+func (b *Bucket) refillLocked() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Minutes() * b.limit
+	if b.tokens > b.limit {
+		b.tokens = b.limit
+	}
+	b.last = now
+}
+
+// Wait blocks until n units are available (respecting ctx) and takes them.
+func (b *Bucket) Wait(ctx context.Context, n float64) error {
+	for {
+		b.mu.Lock()
+		b.refillLocked()
+		if b.tokens >= n {
+			b.tokens -= n
+			b.mu.Unlock()
+			return nil
+		}
+		deficit := n - b.tokens
+		b.mu.Unlock()
+
+		sleep := time.Duration(deficit / b.limit * float64(time.Minute))
+		if sleep < time.Millisecond {
+			sleep = time.Millisecond
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+	}
+}
+
+// Debit takes n units without waiting; the balance may go negative, which
+// simply delays future callers. Used to reconcile estimated vs actual
+// token usage after a call.
+func (b *Bucket) Debit(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked()
+	b.tokens -= n
+}
+
+// Limiter enforces a model's RPM and/or TPM limits; nil buckets are
+// unlimited.
+type Limiter struct {
+	RPM *Bucket
+	TPM *Bucket
+}
+
+// NewLimiter builds a limiter from per-minute limits; zero disables the
+// corresponding bucket.
+func NewLimiter(rpm, tpm int) *Limiter {
+	l := &Limiter{}
+	if rpm > 0 {
+		l.RPM = NewBucket(rpm)
+	}
+	if tpm > 0 {
+		l.TPM = NewBucket(tpm)
+	}
+	return l
+}
+
+// Acquire waits for one request slot and the estimated token budget.
+func (l *Limiter) Acquire(ctx context.Context, estimatedTokens int) error {
+	if l.RPM != nil {
+		if err := l.RPM.Wait(ctx, 1); err != nil {
+			return err
+		}
+	}
+	if l.TPM != nil {
+		if err := l.TPM.Wait(ctx, float64(estimatedTokens)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile adjusts the token bucket once actual usage is known: extra
+// tokens beyond the estimate are debited retroactively, and over-estimates
+// are refunded.
+func (l *Limiter) Reconcile(estimatedTokens, actualTokens int) {
+	if l.TPM != nil && actualTokens != estimatedTokens {
+		l.TPM.Debit(float64(actualTokens - estimatedTokens))
+	}
+}
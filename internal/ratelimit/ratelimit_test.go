@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBucketAllowsBurstThenBlocks(t *testing.T) {
+	b := NewBucket(2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := b.Wait(ctx, 1); err != nil {
+			t.Fatalf("burst call %d: %v", i, err)
+		}
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(short, 1); err == nil {
+		t.Error("third call within the minute should block until ctx expiry")
+	}
+}
+
+func TestBucketRefills(t *testing.T) {
+	b := NewBucket(60000) // 1000/sec so the test refills fast
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	if err := b.Wait(context.Background(), 60000); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	if err := b.Wait(context.Background(), 900); err != nil {
+		t.Errorf("bucket should have refilled ~1000 tokens: %v", err)
+	}
+}
+
+func TestLimiterReconcileDebitsOverage(t *testing.T) {
+	l := NewLimiter(0, 100)
+	if err := l.Acquire(context.Background(), 50); err != nil {
+		t.Fatal(err)
+	}
+	// Actual usage doubled the estimate; the difference is debited and the
+	// bucket dips below the next request's needs.
+	l.Reconcile(50, 150)
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.TPM.Wait(short, 10); err == nil {
+		t.Error("bucket should be exhausted after reconciling the overage")
+	}
+}
+
+func TestLimiterConcurrentUse(t *testing.T) {
+	l := NewLimiter(100000, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = l.Acquire(context.Background(), 0)
+		}()
+	}
+	wg.Wait()
+}
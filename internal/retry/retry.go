@@ -0,0 +1,98 @@
+// Package retry implements the exponential-backoff policy the provider
+// clients apply around HTTP calls.
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// Config controls the backoff schedule.
+type Config struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// JitterRatio is the fraction of each delay that is randomized: 1 is
+	// full jitter, 0 a fixed schedule.
+	JitterRatio float64
+	// OnRetry, when set, is invoked with the attempt number each time a
+	// transient failure is about to be retried.
+	OnRetry func(attempt int)
+}
+
+// Default is the policy applied when a client is not configured otherwise.
+var Default = Config{
+	MaxAttempts: 5,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    3 * time.Second,
+	JitterRatio: 1,
+}
+
+// TransientError marks an error as retryable. Provider clients wrap 429s,
+// 5xx responses, and network failures in it.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string { return e.Err.Error() }
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// Transient wraps err so the retry loop will retry it.
+func Transient(err error) error { return &TransientError{Err: err} }
+
+// IsTransient reports whether err (or anything it wraps) is retryable.
+func IsTransient(err error) bool {
+	var te *TransientError
+	return errors.As(err, &te)
+}
+
+// Do invokes fn until it succeeds, returns a non-transient error, the context
+// is done, or cfg.MaxAttempts is exhausted.
+func Do(ctx context.Context, cfg Config, logger *slog.Logger, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsTransient(err) {
+			return err
+		}
+		if attempt == cfg.MaxAttempts {
+			break
+		}
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt)
+		}
+		delay := backoff(cfg, attempt)
+		logger.Warn("retrying after transient error",
+			"attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+func backoff(cfg Config, attempt int) time.Duration {
+	d := cfg.BaseDelay << uint(attempt-1)
+	if d > cfg.MaxDelay || d <= 0 {
+		d = cfg.MaxDelay
+	}
+	// Jitter keeps concurrent callers from retrying in lockstep; the
+	// configured ratio says how much of the delay is randomized.
+	ratio := cfg.JitterRatio
+	if ratio < 0 {
+		ratio = 0
+	} else if ratio > 1 {
+		ratio = 1
+	}
+	fixed := time.Duration(float64(d) * (1 - ratio))
+	jittered := time.Duration(0)
+	if r := int64(float64(d) * ratio); r > 0 {
+		jittered = time.Duration(rand.Int63n(r) + 1)
+	}
+	return fixed + jittered
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestDoRespectsMaxAttempts(t *testing.T) {
+	calls := 0
+	cfg := Config{MaxAttempts: 3, BaseDelay: time.Microsecond, MaxDelay: time.Millisecond, JitterRatio: 1}
+	err := Do(context.Background(), cfg, slog.Default(), func() error {
+		calls++
+		return Transient(errors.New("boom"))
+	})
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if !IsTransient(err) {
+		t.Errorf("final error should keep its transient marker: %v", err)
+	}
+}
+
+func TestDoSingleAttempt(t *testing.T) {
+	calls := 0
+	cfg := Config{MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second}
+	_ = Do(context.Background(), cfg, slog.Default(), func() error {
+		calls++
+		return Transient(errors.New("boom"))
+	})
+	if calls != 1 {
+		t.Errorf("calls = %d, want no retries with max_attempts 1", calls)
+	}
+}
+
+func TestBackoffJitterRatio(t *testing.T) {
+	cfg := Config{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, JitterRatio: 0}
+	for i := 0; i < 5; i++ {
+		if d := backoff(cfg, 1); d != 100*time.Millisecond {
+			t.Fatalf("zero jitter should be deterministic, got %v", d)
+		}
+	}
+	cfg.JitterRatio = 1
+	for i := 0; i < 20; i++ {
+		if d := backoff(cfg, 1); d > 100*time.Millisecond {
+			t.Fatalf("full jitter exceeded the base delay: %v", d)
+		}
+	}
+}
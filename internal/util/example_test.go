@@ -0,0 +1,404 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateToolParametersExamples(t *testing.T) {
+	type args struct {
+		City  string   `json:"city" example:"Paris"`
+		Days  int      `json:"days" example:"3"`
+		Ratio float64  `json:"ratio" example:"0.5"`
+		Tags  []string `json:"tags" example:"a, b"`
+		Bad   int      `json:"bad" example:"not-a-number"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+
+	want := map[string]any{
+		"city":  "Paris",
+		"days":  3,
+		"ratio": 0.5,
+	}
+	for name, ex := range want {
+		got, ok := byName[name]["examples"].([]any)
+		if !ok || len(got) != 1 || got[0] != ex {
+			t.Errorf("%s examples = %v, want [%v]", name, byName[name]["examples"], ex)
+		}
+	}
+	tags, ok := byName["tags"]["examples"].([]any)
+	if !ok || len(tags) != 1 || !reflect.DeepEqual(tags[0], []any{"a", "b"}) {
+		t.Errorf("tags examples = %v", byName["tags"]["examples"])
+	}
+	if _, ok := byName["bad"]["examples"]; ok {
+		t.Error("unparseable example should be dropped, not emitted as a string")
+	}
+}
+
+func TestGenerateToolParametersNestedStructs(t *testing.T) {
+	type Filter struct {
+		Field string `json:"field" description:"column to filter"`
+		Op    string `json:"op"`
+		Value string `json:"value,omitempty"`
+	}
+	type args struct {
+		Filter  Filter    `json:"filter"`
+		Filters []*Filter `json:"filters"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+
+	filter := byName["filter"]
+	props, ok := filter["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("nested struct not expanded: %v", filter)
+	}
+	if props["field"].(map[string]any)["description"] != "column to filter" {
+		t.Errorf("field schema = %v", props["field"])
+	}
+	req, _ := filter["required"].([]string)
+	if len(req) != 2 { // value is omitempty
+		t.Errorf("required = %v", req)
+	}
+
+	items := byName["filters"]["items"].(map[string]any)
+	if _, ok := items["properties"]; !ok {
+		t.Errorf("slice-of-struct items not expanded: %v", items)
+	}
+}
+
+type selfRef struct {
+	Name string   `json:"name"`
+	Next *selfRef `json:"next,omitempty"`
+}
+
+func TestGenerateToolParametersSelfReferentialType(t *testing.T) {
+	type args struct {
+		Root selfRef `json:"root"`
+	}
+	// Must terminate rather than recurse forever.
+	if _, err := GenerateToolParameters(&args{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnumTags(t *testing.T) {
+	type args struct {
+		Unit  string `json:"unit" enum:"celsius,fahrenheit"`
+		Level int    `json:"level" enum:"1,2,3"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+	if !reflect.DeepEqual(byName["unit"]["enum"], []any{"celsius", "fahrenheit"}) {
+		t.Errorf("unit enum = %v", byName["unit"]["enum"])
+	}
+	if !reflect.DeepEqual(byName["level"]["enum"], []any{1, 2, 3}) {
+		t.Errorf("level enum = %v", byName["level"]["enum"])
+	}
+
+	// The typed output schema path honors the same tag.
+	type out struct {
+		Status string `json:"status" enum:"open,closed"`
+	}
+	js, err := GenerateResponseJSONSchema(out{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(js, `"enum":["open","closed"]`) {
+		t.Errorf("response schema = %s", js)
+	}
+}
+
+func TestConstraintTags(t *testing.T) {
+	type args struct {
+		Count int      `json:"count" minimum:"1" maximum:"100"`
+		Ratio float64  `json:"ratio" exclusiveMinimum:"0"`
+		Name  string   `json:"name" minLength:"2" maxLength:"64" pattern:"^[a-z]+$"`
+		Tags  []string `json:"tags" minItems:"1" maxItems:"5"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+	if byName["count"]["minimum"] != 1.0 || byName["count"]["maximum"] != 100.0 {
+		t.Errorf("count = %v", byName["count"])
+	}
+	if byName["ratio"]["exclusiveMinimum"] != 0.0 {
+		t.Errorf("ratio = %v", byName["ratio"])
+	}
+	if byName["name"]["minLength"] != 2 || byName["name"]["maxLength"] != 64 || byName["name"]["pattern"] != "^[a-z]+$" {
+		t.Errorf("name = %v", byName["name"])
+	}
+	if byName["tags"]["minItems"] != 1 || byName["tags"]["maxItems"] != 5 {
+		t.Errorf("tags = %v", byName["tags"])
+	}
+}
+
+type customMarshaled struct{ v string }
+
+func (c customMarshaled) MarshalJSON() ([]byte, error) { return []byte(`"` + c.v + `"`), nil }
+
+func TestSpecialTypeSchemas(t *testing.T) {
+	type args struct {
+		When   time.Time       `json:"when"`
+		Window time.Duration   `json:"window"`
+		Raw    json.RawMessage `json:"raw"`
+		Blob   []byte          `json:"blob"`
+		Custom customMarshaled `json:"custom"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+	if byName["when"]["type"] != "string" || byName["when"]["format"] != "date-time" {
+		t.Errorf("when = %v", byName["when"])
+	}
+	if byName["window"]["type"] != "string" {
+		t.Errorf("window = %v", byName["window"])
+	}
+	if len(byName["raw"]) != 0 {
+		t.Errorf("raw = %v, want unconstrained schema", byName["raw"])
+	}
+	if byName["blob"]["type"] != "string" {
+		t.Errorf("blob = %v", byName["blob"])
+	}
+	if byName["custom"]["type"] != "string" {
+		t.Errorf("custom marshaler = %v", byName["custom"])
+	}
+
+	// The output-schema path treats timestamps identically so Execute[T]
+	// structs round-trip.
+	type out struct {
+		When time.Time `json:"when"`
+	}
+	js, err := GenerateResponseJSONSchema(out{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(js, `"format":"date-time"`) {
+		t.Errorf("response schema = %s", js)
+	}
+}
+
+type Pagination struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page,omitempty"`
+}
+
+func TestEmbeddedStructPromotion(t *testing.T) {
+	type args struct {
+		Pagination
+		Query string `json:"query"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{} // name -> required
+	for _, p := range params {
+		names[p.Name] = p.Required
+	}
+	if _, ok := names["Pagination"]; ok {
+		t.Error("embedded struct must be promoted, not exposed as one object param")
+	}
+	for _, want := range []string{"page", "per_page", "query"} {
+		if _, ok := names[want]; !ok {
+			t.Errorf("missing promoted field %q in %v", want, names)
+		}
+	}
+	if !names["page"] || names["per_page"] {
+		t.Errorf("requiredness wrong: %v", names)
+	}
+}
+
+func TestEmbeddedPointerAndShadowing(t *testing.T) {
+	type Inner struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type args struct {
+		*Inner
+		Name string `json:"name" description:"outer wins"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sawID bool
+	for _, p := range params {
+		if p.Name == "id" {
+			sawID = true
+		}
+		if p.Name == "name" && p.Description != "outer wins" {
+			t.Errorf("outer field should shadow the promoted one: %+v", p)
+		}
+	}
+	if !sawID {
+		t.Error("embedded pointer fields should be promoted")
+	}
+	count := 0
+	for _, p := range params {
+		if p.Name == "name" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("name appears %d times", count)
+	}
+}
+
+type LineItem struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price,omitempty"`
+}
+
+func TestSliceSchemas(t *testing.T) {
+	type args struct {
+		Items  []LineItem  `json:"items"`
+		Refs   []*LineItem `json:"refs"`
+		Matrix [][]int     `json:"matrix"`
+		Units  []string    `json:"units" enum:"kg,lb"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+
+	for _, name := range []string{"items", "refs"} {
+		items := byName[name]["items"].(map[string]any)
+		props, ok := items["properties"].(map[string]any)
+		if !ok {
+			t.Fatalf("%s element schema not expanded: %v", name, items)
+		}
+		if props["quantity"].(map[string]any)["type"] != "integer" {
+			t.Errorf("%s quantity = %v", name, props["quantity"])
+		}
+		req, _ := items["required"].([]string)
+		if len(req) != 2 { // price is omitempty
+			t.Errorf("%s required = %v", name, req)
+		}
+	}
+
+	inner := byName["matrix"]["items"].(map[string]any)
+	if inner["type"] != "array" || inner["items"].(map[string]any)["type"] != "integer" {
+		t.Errorf("matrix = %v", byName["matrix"])
+	}
+
+	unitItems := byName["units"]["items"].(map[string]any)
+	if !reflect.DeepEqual(unitItems["enum"], []any{"kg", "lb"}) {
+		t.Errorf("units enum = %v", unitItems)
+	}
+}
+
+// TestSliceArgsRoundTrip generates the schema and round-trips sample args
+// through json.Unmarshal into the same struct.
+func TestSliceArgsRoundTrip(t *testing.T) {
+	type args struct {
+		Items []LineItem `json:"items"`
+	}
+	if _, err := GenerateToolParameters(&args{}); err != nil {
+		t.Fatal(err)
+	}
+	var decoded args
+	sample := `{"items": [{"sku": "A1", "quantity": 2, "price": 9.5}, {"sku": "B2", "quantity": 1}]}`
+	if err := json.Unmarshal([]byte(sample), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Items) != 2 || decoded.Items[0].SKU != "A1" || decoded.Items[1].Quantity != 1 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
+
+func TestTypedMapSchemas(t *testing.T) {
+	type args struct {
+		Scores map[string]int      `json:"scores"`
+		Meta   map[string]any      `json:"meta"`
+		Nested map[string]LineItem `json:"nested"`
+	}
+	params, err := GenerateToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]map[string]any{}
+	for _, p := range params {
+		byName[p.Name] = p.Schema
+	}
+	if ap := byName["scores"]["additionalProperties"].(map[string]any); ap["type"] != "integer" {
+		t.Errorf("scores = %v", byName["scores"])
+	}
+	if byName["meta"]["additionalProperties"] != true {
+		t.Errorf("meta = %v, map[string]any keeps plain true", byName["meta"])
+	}
+	nested := byName["nested"]["additionalProperties"].(map[string]any)
+	if _, ok := nested["properties"]; !ok {
+		t.Errorf("nested map value schema not expanded: %v", nested)
+	}
+
+	type bad struct {
+		ByID map[int]string `json:"by_id"`
+	}
+	if _, err := GenerateToolParameters(&bad{}); err == nil {
+		t.Error("non-string map keys should be rejected")
+	}
+}
+
+func TestJSONSchemaTagDescriptions(t *testing.T) {
+	type doc struct {
+		Summary string `json:"summary" jsonschema:"description=one-line summary,title=Summary"`
+		Body    string `json:"body" description:"full text"`
+	}
+	js, err := GenerateResponseJSONSchema(doc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"description":"one-line summary"`, `"title":"Summary"`, `"description":"full text"`} {
+		if !strings.Contains(js, want) {
+			t.Errorf("schema missing %s:\n%s", want, js)
+		}
+	}
+
+	// Nested descriptions survive sanitization; only root meta is
+	// stripped.
+	sanitized, err := SanitizeResponseSchemaJSON(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sanitized, "one-line summary") || !strings.Contains(sanitized, `"title":"Summary"`) {
+		t.Errorf("sanitizer stripped nested docs:\n%s", sanitized)
+	}
+}
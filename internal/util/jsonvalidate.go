@@ -0,0 +1,107 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateAgainstSchema checks a JSON document against the subset of JSON
+// Schema the router generates: types, required properties, enum membership,
+// nested objects, and array items. With strict set, fields not declared in
+// an object's properties are also violations. Violations use the same
+// deterministic formatting as tool-argument validation.
+func ValidateAgainstSchema(data json.RawMessage, schema map[string]any, strict bool) []Violation {
+	return validateNode(data, schema, "", strict)
+}
+
+func validateNode(data json.RawMessage, schema map[string]any, path string, strict bool) []Violation {
+	expected, _ := schema["type"].(string)
+	actual := jsonTypeOf(data)
+	if actual == "null" {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		if expected != "" {
+			return []Violation{{Path: orRoot(path), Code: "null_not_allowed", Expected: expected}}
+		}
+		return nil
+	}
+	if expected != "" && !jsonTypeMatches(expected, actual) {
+		return []Violation{{Path: orRoot(path), Code: "wrong_type", Expected: expected, Actual: actual}}
+	}
+
+	var vs []Violation
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		if v := enumViolation(orRoot(path), data, enum); v != nil {
+			vs = append(vs, *v)
+		}
+	}
+
+	switch actual {
+	case "object":
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return vs
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range requiredNames(schema["required"]) {
+			if _, present := obj[name]; !present {
+				fieldType := ""
+				if sub, ok := props[name].(map[string]any); ok {
+					fieldType, _ = sub["type"].(string)
+				}
+				vs = append(vs, Violation{Path: childPath(path, name), Code: "missing_required", Expected: fieldType})
+			}
+		}
+		for name, raw := range obj {
+			sub, declared := props[name].(map[string]any)
+			if !declared {
+				if strict && props != nil {
+					names := make([]string, 0, len(props))
+					for n := range props {
+						names = append(names, n)
+					}
+					sort.Strings(names)
+					vs = append(vs, Violation{Path: childPath(path, name), Code: "unknown_field",
+						Expected: "one of [" + strings.Join(names, ", ") + "]"})
+				}
+				continue
+			}
+			vs = append(vs, validateNode(raw, sub, childPath(path, name), strict)...)
+		}
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return vs
+		}
+		var arr []json.RawMessage
+		if err := json.Unmarshal(data, &arr); err != nil {
+			return vs
+		}
+		for i, el := range arr {
+			vs = append(vs, validateNode(el, items, fmt.Sprintf("%s[%d]", path, i), strict)...)
+		}
+	}
+	return vs
+}
+
+// requiredNames tolerates both []any (schemas decoded from JSON) and
+// []string (schemas built in process).
+func requiredNames(v any) []string {
+	switch r := v.(type) {
+	case []string:
+		return r
+	case []any:
+		out := make([]string, 0, len(r))
+		for _, e := range r {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
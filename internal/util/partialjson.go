@@ -0,0 +1,71 @@
+package util
+
+import "strings"
+
+// CompletePartialJSON closes any strings, objects, and arrays left open in a
+// JSON prefix, producing a candidate document for best-effort parsing of
+// streamed output. Trailing separators that would make the completion invalid
+// (a dangling comma, colon, or bare key) are trimmed first. The result is not
+// guaranteed to parse; callers should treat failures as "no partial yet".
+func CompletePartialJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+
+	var stack []byte
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if inString {
+		if escaped {
+			s = s[:len(s)-1]
+		}
+		s += `"`
+	}
+	s = strings.TrimRight(s, " \t\n\r")
+	s = strings.TrimRight(s, ",:")
+	// An object member that is a bare string with no colon yet is a dangling
+	// key: drop it so the completion stays valid.
+	if len(stack) > 0 && stack[len(stack)-1] == '{' {
+		if i := strings.LastIndexAny(s, "{,"); i >= 0 && i < len(s)-1 {
+			tail := strings.TrimSpace(s[i+1:])
+			if strings.HasPrefix(tail, `"`) && strings.HasSuffix(tail, `"`) && !strings.Contains(tail, ":") {
+				s = strings.TrimRight(s[:i+1], ",")
+			}
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			s += "}"
+		} else {
+			s += "]"
+		}
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompletePartialJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"a": 1`, `{"a": 1}`},
+		{`{"a": "unterminated`, `{"a": "unterminated"}`},
+		{`{"items": ["one", "tw`, `{"items": ["one", "tw"]}`},
+		{`{"a": 1, "b"`, `{"a": 1}`},
+		{`{"a": 1,`, `{"a": 1}`},
+		{`{"a": {"b": [1, 2`, `{"a": {"b": [1, 2]}}`},
+		{`{"a": "x"}`, `{"a": "x"}`},
+		{`[1, 2`, `[1, 2]`},
+	}
+	for _, tt := range tests {
+		got := CompletePartialJSON(tt.in)
+		if got != tt.want {
+			t.Errorf("CompletePartialJSON(%q) = %q, want %q", tt.in, got, tt.want)
+			continue
+		}
+		if !json.Valid([]byte(got)) {
+			t.Errorf("CompletePartialJSON(%q) = %q is not valid JSON", tt.in, got)
+		}
+	}
+}
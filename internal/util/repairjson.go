@@ -0,0 +1,183 @@
+package util
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RepairJSON attempts to recover a JSON document from model output; see
+// RepairJSONDetailed for what is fixed.
+func RepairJSON(s string) string {
+	out, _ := RepairJSONDetailed(s)
+	return out
+}
+
+// RepairJSONDetailed recovers a JSON document from model output decorated
+// with prose or markdown and fixes the specific defect classes real models
+// produce: trailing commas before } or ], single-quoted strings, unquoted
+// object keys, and literal newlines/tabs inside string values. It never
+// invents content — structurally missing data is left broken. The boolean
+// reports whether any repair was applied, so callers can track repair
+// frequency.
+func RepairJSONDetailed(s string) (string, bool) {
+	original := s
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		if i := strings.Index(s, "\n"); i >= 0 {
+			s = s[i+1:]
+		}
+		if i := strings.LastIndex(s, "```"); i >= 0 {
+			s = s[:i]
+		}
+		s = strings.TrimSpace(s)
+	}
+	if extracted, ok := extractFirstJSON(s); ok {
+		s = extracted
+	} else {
+		// No complete value found; fall back to the old outer-brace trim so
+		// truncated output still reaches the token repair below.
+		start := strings.IndexAny(s, "{[")
+		if start < 0 {
+			return s, s != original
+		}
+		var end int
+		if s[start] == '{' {
+			end = strings.LastIndex(s, "}")
+		} else {
+			end = strings.LastIndex(s, "]")
+		}
+		if end > start {
+			s = s[start : end+1]
+		}
+	}
+	if json.Valid([]byte(s)) {
+		return s, s != original
+	}
+	fixed := repairTokens(s)
+	return fixed, fixed != original
+}
+
+// repairTokens runs a tolerant scan fixing quote, comma, and key defects
+// while respecting string and escape state.
+func repairTokens(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inStr := false
+	var quote byte
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inStr {
+			switch {
+			case escaped:
+				b.WriteByte(c)
+				escaped = false
+			case c == '\\':
+				b.WriteByte(c)
+				escaped = true
+			case c == quote:
+				b.WriteByte('"')
+				inStr = false
+			case c == '"': // a double quote inside a single-quoted string
+				b.WriteString(`\"`)
+			case c == '\n':
+				b.WriteString(`\n`)
+			case c == '\r':
+				b.WriteString(`\r`)
+			case c == '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteByte(c)
+			}
+			continue
+		}
+		switch {
+		case c == '"' || c == '\'':
+			inStr = true
+			quote = c
+			b.WriteByte('"')
+		case c == ',':
+			j := i + 1
+			for j < len(s) && isSpace(s[j]) {
+				j++
+			}
+			if j < len(s) && (s[j] == '}' || s[j] == ']') {
+				continue // trailing comma: drop it
+			}
+			b.WriteByte(c)
+		case isIdentStart(c):
+			j := i
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			word := s[i:j]
+			k := j
+			for k < len(s) && isSpace(s[k]) {
+				k++
+			}
+			if k < len(s) && s[k] == ':' && word != "true" && word != "false" && word != "null" {
+				// Unquoted object key.
+				b.WriteByte('"')
+				b.WriteString(word)
+				b.WriteByte('"')
+			} else {
+				b.WriteString(word)
+			}
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// extractFirstJSON scans for the first complete top-level JSON value,
+// tracking brace/bracket depth and string/escape state so braces inside
+// string literals or in surrounding prose don't confuse the extraction.
+func extractFirstJSON(s string) (string, bool) {
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return "", false
+	}
+	depth := 0
+	inStr := false
+	var quote byte
+	escaped := false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inStr {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == quote:
+				inStr = false
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			inStr = true
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], true
+			}
+		}
+	}
+	return "", false
+}
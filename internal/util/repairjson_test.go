@@ -0,0 +1,160 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestRepairJSONDetailed covers the real-world defect classes the repair
+// pass fixes, asserting the output parses and the repaired flag is
+// reported.
+func TestRepairJSONDetailed(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           string
+		want         string
+		wantRepaired bool
+	}{
+		{
+			name:         "already valid",
+			in:           `{"a": 1}`,
+			want:         `{"a": 1}`,
+			wantRepaired: false,
+		},
+		{
+			name:         "code fences",
+			in:           "```json\n{\"a\": 1}\n```",
+			want:         `{"a": 1}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "prose around object",
+			in:           `Sure! Here you go: {"a": 1} Hope that helps.`,
+			want:         `{"a": 1}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "trailing comma in object",
+			in:           `{"a": 1, "b": 2,}`,
+			want:         `{"a": 1, "b": 2}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "trailing comma in array",
+			in:           `{"items": [1, 2, 3, ]}`,
+			want:         `{"items": [1, 2, 3 ]}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "single-quoted strings",
+			in:           `{'name': 'O"Brien'}`,
+			want:         `{"name": "O\"Brien"}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "unquoted keys",
+			in:           `{name: "x", count: 2}`,
+			want:         `{"name": "x", "count": 2}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "literal newline in string",
+			in:           "{\"text\": \"line one\nline two\"}",
+			want:         `{"text": "line one\nline two"}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "keywords not quoted as keys",
+			in:           `{flag: true, other: null}`,
+			want:         `{"flag": true, "other": null}`,
+			wantRepaired: true,
+		},
+		{
+			name:         "combination",
+			in:           "```\n{unit: 'celsius', values: [1, 2,],}\n```",
+			want:         `{"unit": "celsius", "values": [1, 2]}`,
+			wantRepaired: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, repaired := RepairJSONDetailed(tt.in)
+			if got != tt.want {
+				t.Errorf("RepairJSONDetailed() = %q, want %q", got, tt.want)
+			}
+			if repaired != tt.wantRepaired {
+				t.Errorf("repaired = %v, want %v", repaired, tt.wantRepaired)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("output is not valid JSON: %q", got)
+			}
+		})
+	}
+}
+
+// TestRepairJSONDoesNotInventContent pins the deliberate limitation:
+// structurally missing data stays broken rather than being guessed at.
+func TestRepairJSONDoesNotInventContent(t *testing.T) {
+	in := `{"a": }`
+	got := RepairJSON(in)
+	if json.Valid([]byte(got)) {
+		t.Errorf("missing value should not be repaired, got %q", got)
+	}
+}
+
+// TestBraceAwareExtraction covers the pathological prose cases the naive
+// first-{-to-last-} trim got wrong.
+func TestBraceAwareExtraction(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "prose with braces after the value",
+			in:   `Here is {"a":1}. Note: use {braces} carefully.`,
+			want: `{"a":1}`,
+		},
+		{
+			name: "multiple candidate objects picks the first",
+			in:   `{"first":1} and also {"second":2}`,
+			want: `{"first":1}`,
+		},
+		{
+			name: "braces inside string literals",
+			in:   `result: {"code":"if (x) { y(); }","ok":true} trailing {junk}`,
+			want: `{"code":"if (x) { y(); }","ok":true}`,
+		},
+		{
+			name: "nested objects",
+			in:   `answer {"a":{"b":[1,{"c":2}]}} extra }`,
+			want: `{"a":{"b":[1,{"c":2}]}}`,
+		},
+		{
+			name: "array value",
+			in:   `the list: [1, 2, 3] and more ]`,
+			want: `[1, 2, 3]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RepairJSON(tt.in)
+			if got != tt.want {
+				t.Errorf("RepairJSON(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("not valid JSON: %q", got)
+			}
+		})
+	}
+}
+
+func TestExtractionFallbackForTruncated(t *testing.T) {
+	// No complete value: the old trim keeps whatever is there so repair
+	// (or the truncation path) can deal with it.
+	in := `{"a": [1, 2`
+	got := RepairJSON(in)
+	if got == "" {
+		t.Error("truncated input should not be discarded entirely")
+	}
+}
@@ -0,0 +1,193 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaWarning records something SanitizeResponseSchemaDetailed had to
+// change to make a schema provider-safe.
+type SchemaWarning struct {
+	// Path locates the change, "" for the root.
+	Path    string
+	Message string
+}
+
+// unsupportedKeywords are JSON Schema keywords neither provider's
+// structured-output dialect accepts; they are stripped wherever they appear.
+var unsupportedKeywords = []string{
+	"$comment", "$anchor", "$dynamicRef", "$dynamicAnchor",
+	"if", "then", "else", "not", "allOf", "oneOf",
+	"patternProperties", "dependencies", "dependentSchemas", "dependentRequired",
+	"unevaluatedProperties", "contentEncoding", "contentMediaType",
+}
+
+// rootMetaKeywords are additionally stripped at the root only; nested titles
+// and descriptions are kept because providers use them as field hints.
+var rootMetaKeywords = []string{"$schema", "$id", "title"}
+
+// SanitizeResponseSchemaDetailed applies every rule the router uses to make
+// a JSON Schema safe for provider structured-output modes: local $ref
+// references are inlined (and $defs/definitions dropped), unsupported
+// keywords are stripped, root meta keywords are removed, and a non-object
+// root is coerced to an object. Each change is reported as a warning. It
+// errors on schemas that cannot be made provider-safe: invalid JSON,
+// non-local or unresolvable $refs, and circular references.
+func SanitizeResponseSchemaDetailed(schema string) (string, []SchemaWarning, error) {
+	var root map[string]any
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		return "", nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+
+	defs := collectDefs(root)
+	var warnings []SchemaWarning
+
+	inlined, err := inlineRefs(root, defs, "", map[string]bool{}, &warnings)
+	if err != nil {
+		return "", nil, err
+	}
+	root = inlined.(map[string]any)
+
+	for _, k := range []string{"$defs", "definitions"} {
+		if _, ok := root[k]; ok {
+			delete(root, k)
+			warnings = append(warnings, SchemaWarning{Path: "", Message: "removed " + k + " after inlining references"})
+		}
+	}
+	for _, k := range rootMetaKeywords {
+		if _, ok := root[k]; ok {
+			delete(root, k)
+			warnings = append(warnings, SchemaWarning{Path: "", Message: "removed root meta keyword " + k})
+		}
+	}
+	stripUnsupported(root, "", &warnings)
+	if t, ok := root["type"].(string); !ok || t == "" {
+		root["type"] = "object"
+		warnings = append(warnings, SchemaWarning{Path: "", Message: "coerced root to object schema"})
+	}
+
+	b, err := json.Marshal(root)
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Path != warnings[j].Path {
+			return warnings[i].Path < warnings[j].Path
+		}
+		return warnings[i].Message < warnings[j].Message
+	})
+	return string(b), warnings, nil
+}
+
+func collectDefs(root map[string]any) map[string]any {
+	defs := map[string]any{}
+	for _, section := range []string{"$defs", "definitions"} {
+		if m, ok := root[section].(map[string]any); ok {
+			for name, def := range m {
+				defs["#/"+section+"/"+name] = def
+			}
+		}
+	}
+	return defs
+}
+
+// inlineRefs replaces {"$ref": "#/$defs/X"} nodes with the referenced
+// definition, erroring on non-local, unresolvable, or circular references.
+func inlineRefs(node any, defs map[string]any, path string, active map[string]bool, warnings *[]SchemaWarning) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		if ref, ok := n["$ref"].(string); ok {
+			if !strings.HasPrefix(ref, "#/") {
+				return nil, fmt.Errorf("schema at %q uses non-local $ref %q", orRoot(path), ref)
+			}
+			target, ok := defs[ref]
+			if !ok {
+				return nil, fmt.Errorf("schema at %q references unknown definition %q", orRoot(path), ref)
+			}
+			if active[ref] {
+				return nil, fmt.Errorf("schema at %q has circular reference through %q", orRoot(path), ref)
+			}
+			active[ref] = true
+			defer delete(active, ref)
+			*warnings = append(*warnings, SchemaWarning{Path: path, Message: "inlined " + ref})
+			// Inline a copy so shared definitions stay independent.
+			return inlineRefs(deepCopy(target), defs, path, active, warnings)
+		}
+		for k, v := range n {
+			if k == "$defs" || k == "definitions" {
+				continue
+			}
+			inlined, err := inlineRefs(v, defs, childPath(path, k), active, warnings)
+			if err != nil {
+				return nil, err
+			}
+			n[k] = inlined
+		}
+		return n, nil
+	case []any:
+		for i, v := range n {
+			inlined, err := inlineRefs(v, defs, fmt.Sprintf("%s[%d]", path, i), active, warnings)
+			if err != nil {
+				return nil, err
+			}
+			n[i] = inlined
+		}
+		return n, nil
+	default:
+		return node, nil
+	}
+}
+
+func stripUnsupported(node any, path string, warnings *[]SchemaWarning) {
+	switch n := node.(type) {
+	case map[string]any:
+		for _, k := range unsupportedKeywords {
+			if _, ok := n[k]; ok {
+				delete(n, k)
+				*warnings = append(*warnings, SchemaWarning{Path: path, Message: "removed unsupported keyword " + k})
+			}
+		}
+		for k, v := range n {
+			stripUnsupported(v, childPath(path, k), warnings)
+		}
+	case []any:
+		for i, v := range n {
+			stripUnsupported(v, fmt.Sprintf("%s[%d]", path, i), warnings)
+		}
+	}
+}
+
+func deepCopy(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(n))
+		for k, vv := range n {
+			out[k] = deepCopy(vv)
+		}
+		return out
+	case []any:
+		out := make([]any, len(n))
+		for i, vv := range n {
+			out[i] = deepCopy(vv)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func childPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
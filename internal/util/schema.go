@@ -0,0 +1,107 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// GenerateResponseJSONSchema reflects a JSON Schema for the given value's
+// type, suitable for use as a provider responseSchema after sanitization.
+func GenerateResponseJSONSchema(v any) (string, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "", fmt.Errorf("cannot generate schema for nil value")
+	}
+	schema, err := generateResponseSchemaForType(t)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// generateResponseSchemaForType recursively builds a schema for output types.
+// Unlike tool parameters, response schemas descend into nested structs so the
+// model sees the full shape it must produce.
+func generateResponseSchemaForType(t reflect.Type) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if special, ok := specialTypeSchema(t); ok {
+		return special, nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		var required []string
+		for _, f := range promotedFields(t) {
+			name, opts := parseJSONTag(f)
+			if name == "-" {
+				continue
+			}
+			sub, err := generateResponseSchemaForType(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if d, title := fieldDocTags(f); d != "" || title != "" {
+				if d != "" {
+					sub["description"] = d
+				}
+				if title != "" {
+					sub["title"] = title
+				}
+			}
+			if f.Type.Kind() == reflect.Pointer {
+				// Mirror ToolParameter.Nullable: an explicit null is a
+				// valid value for pointer fields, and the output validator
+				// honors the nullable keyword.
+				sub["nullable"] = true
+			}
+			applyEnumTag(sub, f)
+			applyConstraintTags(sub, f)
+			properties[name] = sub
+			if f.Type.Kind() != reflect.Pointer && !opts.omitempty {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema, nil
+	case reflect.Slice, reflect.Array:
+		items, err := generateResponseSchemaForType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object", "additionalProperties": true}, nil
+		}
+		values, err := generateResponseSchemaForType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	default:
+		return generateSchemaForType(t)
+	}
+}
+
+// SanitizeResponseSchemaJSON prepares a reflected or user-supplied JSON
+// Schema for submission as a provider response schema. It applies the full
+// rule set from SanitizeResponseSchemaDetailed and discards the warnings;
+// the two must stay in sync so customers can pre-validate schemas with
+// exactly the rules the router will apply.
+func SanitizeResponseSchemaJSON(schema string) (string, error) {
+	sanitized, _, err := SanitizeResponseSchemaDetailed(schema)
+	return sanitized, err
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// DynamicSchema marks types whose schema can change between calls; they
+// bypass the per-type caches.
+type DynamicSchema interface {
+	DynamicSchema()
+}
+
+var (
+	responseSchemaCache sync.Map // reflect.Type -> string (sanitized)
+	toolParamsCache     sync.Map // reflect.Type -> []core.ToolParameter
+)
+
+// CachedResponseSchema generates and sanitizes the response schema for v's
+// type, caching the provider-agnostic result per reflect.Type so repeated
+// Execute calls with the same type don't pay reflection again. Cached
+// parameter schemas are shared; callers must treat them as read-only.
+func CachedResponseSchema(v any) (string, error) {
+	t := reflect.TypeOf(v)
+	_, dynamic := v.(DynamicSchema)
+	if t != nil && !dynamic {
+		if cached, ok := responseSchemaCache.Load(t); ok {
+			return cached.(string), nil
+		}
+	}
+	schema, err := GenerateResponseJSONSchema(v)
+	if err != nil {
+		return "", err
+	}
+	sanitized, err := SanitizeResponseSchemaJSON(schema)
+	if err != nil {
+		return "", err
+	}
+	if t != nil && !dynamic {
+		responseSchemaCache.Store(t, sanitized)
+	}
+	return sanitized, nil
+}
+
+// CachedToolParameters is GenerateToolParameters with a per-type cache.
+// The returned slice and its schema maps are shared; callers must treat
+// them as read-only.
+func CachedToolParameters(v any) ([]core.ToolParameter, error) {
+	t := reflect.TypeOf(v)
+	_, dynamic := v.(DynamicSchema)
+	if t != nil && !dynamic {
+		if cached, ok := toolParamsCache.Load(t); ok {
+			return cached.([]core.ToolParameter), nil
+		}
+	}
+	params, err := GenerateToolParameters(v)
+	if err != nil {
+		return nil, err
+	}
+	if t != nil && !dynamic {
+		toolParamsCache.Store(t, params)
+	}
+	return params, nil
+}
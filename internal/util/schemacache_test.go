@@ -0,0 +1,76 @@
+package util
+
+import (
+	"testing"
+)
+
+type cachedOut struct {
+	Name string `json:"name"`
+}
+
+type dynamicOut struct {
+	Name string `json:"name"`
+}
+
+func (dynamicOut) DynamicSchema() {}
+
+func TestCachedResponseSchema(t *testing.T) {
+	a, err := CachedResponseSchema(cachedOut{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CachedResponseSchema(cachedOut{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("cache returned different schemas:\n%s\n%s", a, b)
+	}
+	// Dynamic types bypass the cache without error.
+	if _, err := CachedResponseSchema(dynamicOut{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCachedToolParameters(t *testing.T) {
+	type args struct {
+		Q string `json:"q"`
+	}
+	a, err := CachedToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CachedToolParameters(&args{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &a[0] != &b[0] {
+		t.Error("second call should return the cached slice")
+	}
+}
+
+func BenchmarkResponseSchema(b *testing.B) {
+	type wide struct {
+		A, B, C, D string
+		E, F       int
+		Nested     cachedOut
+	}
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, err := GenerateResponseJSONSchema(wide{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := SanitizeResponseSchemaJSON(s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := CachedResponseSchema(wide{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,399 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// GenerateToolParameters reflects over a tool's Parameters struct (or pointer
+// to struct) and produces the provider-agnostic parameter descriptions.
+//
+// Field names follow encoding/json conventions: the json tag wins, untagged
+// fields use the Go name. A field is required unless it is a pointer or its
+// json tag carries omitempty. The description tag supplies the parameter
+// description shown to the model.
+func GenerateToolParameters(v any) ([]core.ToolParameter, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("tool parameters value is nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tool parameters must be a struct, got %s", t.Kind())
+	}
+
+	var params []core.ToolParameter
+	for _, f := range promotedFields(t) {
+		name, opts := parseJSONTag(f)
+		if name == "-" {
+			continue
+		}
+		schema, err := generateSchemaForType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if ex := f.Tag.Get("example"); ex != "" {
+			if v, perr := parseExample(f.Type, ex); perr == nil {
+				schema["examples"] = []any{v}
+			}
+		}
+		applyEnumTag(schema, f)
+		applyConstraintTags(schema, f)
+		description, title := fieldDocTags(f)
+		if title != "" {
+			schema["title"] = title
+		}
+		params = append(params, core.ToolParameter{
+			Name:        name,
+			Description: description,
+			Required:    f.Type.Kind() != reflect.Pointer && !opts.omitempty,
+			Nullable:    f.Type.Kind() == reflect.Pointer,
+			Schema:      schema,
+		})
+	}
+	return params, nil
+}
+
+type jsonTagOpts struct {
+	omitempty bool
+}
+
+func parseJSONTag(f reflect.StructField) (string, jsonTagOpts) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, jsonTagOpts{}
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	var opts jsonTagOpts
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			opts.omitempty = true
+		}
+	}
+	return name, opts
+}
+
+// promotedFields lists a struct's fields with anonymous embedded structs
+// flattened the way encoding/json promotes them: shallower fields shadow
+// deeper ones, tagged embedded fields stay as named members, and ambiguous
+// promoted fields at equal depth are dropped. This keeps generated schemas
+// aligned with how the arguments unmarshal back into the struct.
+func promotedFields(t reflect.Type) []reflect.StructField {
+	type candidate struct {
+		field reflect.StructField
+		depth int
+		order int
+	}
+	byName := map[string][]candidate{}
+	var orderCounter int
+
+	type queueEntry struct {
+		t     reflect.Type
+		depth int
+	}
+	queue := []queueEntry{{t, 0}}
+	visited := map[reflect.Type]bool{}
+	for len(queue) > 0 {
+		entry := queue[0]
+		queue = queue[1:]
+		if visited[entry.t] {
+			continue
+		}
+		visited[entry.t] = true
+		for i := 0; i < entry.t.NumField(); i++ {
+			f := entry.t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+				queue = append(queue, queueEntry{ft, entry.depth + 1})
+				continue
+			}
+			name, _ := parseJSONTag(f)
+			if name == "-" {
+				continue
+			}
+			orderCounter++
+			byName[name] = append(byName[name], candidate{f, entry.depth, orderCounter})
+		}
+	}
+
+	var out []candidate
+	for _, cands := range byName {
+		best := cands[0]
+		ambiguous := false
+		for _, c := range cands[1:] {
+			switch {
+			case c.depth < best.depth:
+				best, ambiguous = c, false
+			case c.depth == best.depth:
+				ambiguous = true
+			}
+		}
+		if ambiguous {
+			continue // encoding/json drops equal-depth conflicts
+		}
+		out = append(out, best)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].order < out[j].order })
+	fields := make([]reflect.StructField, len(out))
+	for i, c := range out {
+		fields[i] = c.field
+	}
+	return fields
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	rawMsgType    = reflect.TypeOf(json.RawMessage(nil))
+	byteSliceType = reflect.TypeOf([]byte(nil))
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// specialTypeSchema maps well-known types that would otherwise reflect into
+// garbage: time.Time marshals as RFC 3339, time.Duration as nanoseconds (we
+// accept and document the Go duration string instead), json.RawMessage is
+// arbitrary JSON, and []byte is base64. Other json.Marshaler implementers
+// fall back to a string schema with a hint, since their wire form is
+// opaque to reflection.
+func specialTypeSchema(t reflect.Type) (map[string]any, bool) {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, true
+	case durationType:
+		return map[string]any{"type": "string", "description": "duration string such as \"300ms\" or \"1h30m\""}, true
+	case rawMsgType:
+		return map[string]any{}, true
+	case byteSliceType:
+		return map[string]any{"type": "string", "description": "base64-encoded bytes"}, true
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return map[string]any{"type": "string", "description": "value with a custom JSON representation"}, true
+	}
+	return nil, false
+}
+
+// fieldDocTags reads a field's description and title from either the plain
+// description tag or invopop-style jsonschema:"description=...,title=..."
+// tags, so structs annotated for other reflectors keep their docs.
+func fieldDocTags(f reflect.StructField) (description, title string) {
+	description = f.Tag.Get("description")
+	for _, part := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "description":
+			if description == "" {
+				description = value
+			}
+		case "title":
+			title = value
+		}
+	}
+	return description, title
+}
+
+// applyEnumTag reads an enum:"a,b,c" struct tag into the schema, converting
+// values to the field's JSON type so integer enums come out as numbers.
+func applyEnumTag(schema map[string]any, f reflect.StructField) {
+	tag := f.Tag.Get("enum")
+	if tag == "" {
+		return
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// On a slice field the enum constrains each element, not the array.
+	elemType := t
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		elemType = t.Elem()
+	}
+	var values []any
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if v, err := parseExample(elemType, part); err == nil {
+			values = append(values, v)
+		} else {
+			return // a single unparseable value invalidates the tag
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if items, ok := schema["items"].(map[string]any); ok {
+			items["enum"] = values
+		}
+		return
+	}
+	schema["enum"] = values
+}
+
+// applyConstraintTags reads validation struct tags — minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern,
+// minItems, maxItems — into the schema fragment. Providers use these hints
+// to produce valid arguments and local validation can enforce them.
+func applyConstraintTags(schema map[string]any, f reflect.StructField) {
+	for _, tag := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if v := f.Tag.Get(tag); v != "" {
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				schema[tag] = n
+			}
+		}
+	}
+	for _, tag := range []string{"minLength", "maxLength", "minItems", "maxItems"} {
+		if v := f.Tag.Get(tag); v != "" {
+			if n, err := strconv.Atoi(v); err == nil {
+				schema[tag] = n
+			}
+		}
+	}
+	if p := f.Tag.Get("pattern"); p != "" {
+		schema["pattern"] = p
+	}
+}
+
+// parseExample converts an example struct-tag value to the field's JSON
+// type, so `example:"3"` on an int lands as the number 3 and a
+// comma-separated tag on a slice becomes an array.
+func parseExample(t reflect.Type, raw string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.Atoi(raw)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	case reflect.Slice, reflect.Array:
+		var out []any
+		for _, part := range strings.Split(raw, ",") {
+			v, err := parseExample(t.Elem(), strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("no example representation for %s", t)
+	}
+}
+
+// maxSchemaDepth cuts off self-referential types; beyond it a bare object
+// schema is emitted instead of recursing forever.
+const maxSchemaDepth = 16
+
+// generateSchemaForType produces the JSON Schema fragment for a single Go
+// type, descending into nested structs, slices, and pointers so the model
+// sees full property information.
+func generateSchemaForType(t reflect.Type) (map[string]any, error) {
+	return generateSchemaForTypeDepth(t, 0)
+}
+
+func generateSchemaForTypeDepth(t reflect.Type, depth int) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if depth > maxSchemaDepth {
+		return map[string]any{"type": "object", "additionalProperties": true}, nil
+	}
+	if special, ok := specialTypeSchema(t); ok {
+		return special, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := generateSchemaForTypeDepth(t.Elem(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		properties := map[string]any{}
+		var required []string
+		for _, f := range promotedFields(t) {
+			name, opts := parseJSONTag(f)
+			if name == "-" {
+				continue
+			}
+			sub, err := generateSchemaForTypeDepth(f.Type, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if d, title := fieldDocTags(f); d != "" || title != "" {
+				if d != "" {
+					sub["description"] = d
+				}
+				if title != "" {
+					sub["title"] = title
+				}
+			}
+			if f.Type.Kind() == reflect.Pointer {
+				sub["nullable"] = true
+			}
+			applyEnumTag(sub, f)
+			applyConstraintTags(sub, f)
+			properties[name] = sub
+			if f.Type.Kind() != reflect.Pointer && !opts.omitempty {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return schema, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object", "additionalProperties": true}, nil
+		}
+		values, err := generateSchemaForTypeDepth(t.Elem(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %s", t)
+	}
+}
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// Violation records one way a JSON value failed validation against a schema.
+// Violations are fed back to the model, so their rendering must be compact
+// and deterministic; see FormatViolations.
+type Violation struct {
+	// Path is the dotted JSON path of the offending field, e.g. "filter.op".
+	Path string
+	// Code classifies the failure: "missing_required", "wrong_type".
+	Code string
+	// Expected describes what the schema wanted, e.g. a type name.
+	Expected string
+	// Actual describes what was found, empty for missing fields.
+	Actual string
+}
+
+// FormatViolations renders violations in the fixed, provider-agnostic format
+// the router feeds back to the model. Output is deterministic: one line per
+// violation, sorted by path then code, so identical failures always produce
+// identical text. Free-form Go error strings confuse smaller models; this
+// format enumerates exactly which fields are wrong and what was expected.
+func FormatViolations(vs []Violation) string {
+	sorted := make([]Violation, len(vs))
+	copy(sorted, vs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+	var b strings.Builder
+	for i, v := range sorted {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		switch v.Code {
+		case "missing_required":
+			fmt.Fprintf(&b, "- %s: missing required field (expected %s)", v.Path, v.Expected)
+		case "wrong_type":
+			fmt.Fprintf(&b, "- %s: expected %s, got %s", v.Path, v.Expected, v.Actual)
+		case "null_not_allowed":
+			fmt.Fprintf(&b, "- %s: null is not allowed (expected %s)", v.Path, v.Expected)
+		case "invalid_enum":
+			fmt.Fprintf(&b, "- %s: %q is not allowed (expected %s)", v.Path, v.Actual, v.Expected)
+		case "unknown_field":
+			fmt.Fprintf(&b, "- %s: unknown field (expected %s)", v.Path, v.Expected)
+		default:
+			fmt.Fprintf(&b, "- %s: %s (expected %s)", v.Path, v.Code, v.Expected)
+		}
+	}
+	return b.String()
+}
+
+// ValidateToolArgs checks raw model-supplied arguments against a tool's
+// declared parameters: every required parameter must be present, and present
+// values must match the declared JSON type. With strict set, an explicit
+// null for a required non-nullable parameter is a violation, and unknown
+// (typically misspelled) fields are flagged with the list of valid names;
+// otherwise nulls decode to zero values and unknown fields vanish the way
+// encoding/json always has. It returns nil when the arguments are
+// acceptable.
+func ValidateToolArgs(raw json.RawMessage, def core.ToolDef, strict bool) []Violation {
+	// Some providers send an empty arguments payload for zero-parameter
+	// tools; treat it as an empty object.
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = json.RawMessage("{}")
+	}
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return []Violation{{Path: "(arguments)", Code: "wrong_type", Expected: "object", Actual: jsonTypeOf(raw)}}
+	}
+	var vs []Violation
+	if strict { // strict mode also flags unknown (misspelled) fields
+		known := map[string]bool{}
+		names := make([]string, 0, len(def.Parameters))
+		for _, p := range def.Parameters {
+			known[p.Name] = true
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		valid := "one of [" + strings.Join(names, ", ") + "]"
+		for name := range args {
+			if !known[name] {
+				vs = append(vs, Violation{Path: name, Code: "unknown_field", Expected: valid})
+			}
+		}
+	}
+	for _, p := range def.Parameters {
+		val, ok := args[p.Name]
+		expected, _ := p.Schema["type"].(string)
+		if !ok {
+			if p.Required {
+				vs = append(vs, Violation{Path: p.Name, Code: "missing_required", Expected: expected})
+			}
+			continue
+		}
+		actual := jsonTypeOf(val)
+		if actual == "null" {
+			// json.Unmarshal leaves the field at its zero value, so the tool
+			// would silently run with "" or 0. Only strict mode reports it,
+			// and only for parameters that aren't nullable.
+			if strict && p.Required && !p.Nullable {
+				vs = append(vs, Violation{Path: p.Name, Code: "null_not_allowed", Expected: expected})
+			}
+			continue
+		}
+		if expected == "" {
+			continue
+		}
+		if !jsonTypeMatches(expected, actual) {
+			vs = append(vs, Violation{Path: p.Name, Code: "wrong_type", Expected: expected, Actual: actual})
+			continue
+		}
+		if enum, ok := p.Schema["enum"].([]any); ok && len(enum) > 0 {
+			if v := enumViolation(p.Name, val, enum); v != nil {
+				vs = append(vs, *v)
+			}
+		}
+	}
+	return vs
+}
+
+// enumViolation checks a decoded value against the schema's enum list.
+func enumViolation(name string, raw json.RawMessage, enum []any) *Violation {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+	allowed := make([]string, 0, len(enum))
+	for _, e := range enum {
+		if reflect.DeepEqual(v, e) {
+			return nil
+		}
+		allowed = append(allowed, fmt.Sprint(e))
+	}
+	return &Violation{Path: name, Code: "invalid_enum", Expected: "one of [" + strings.Join(allowed, ", ") + "]", Actual: fmt.Sprint(v)}
+}
+
+// jsonTypeOf names the JSON type of a raw value using schema vocabulary.
+func jsonTypeOf(raw json.RawMessage) string {
+	t := strings.TrimSpace(string(raw))
+	if t == "" {
+		return "null"
+	}
+	switch t[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		if strings.ContainsAny(t, ".eE") {
+			return "number"
+		}
+		return "integer"
+	}
+}
+
+func jsonTypeMatches(expected, actual string) bool {
+	if expected == actual {
+		return true
+	}
+	// JSON integers are valid numbers.
+	return expected == "number" && actual == "integer"
+}
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func weatherDef() core.ToolDef {
+	return core.ToolDef{
+		Name: "get_weather",
+		Parameters: []core.ToolParameter{
+			{Name: "location", Required: true, Schema: map[string]any{"type": "string"}},
+			{Name: "unit", Required: false, Schema: map[string]any{"type": "string"}},
+			{Name: "days", Required: true, Schema: map[string]any{"type": "integer"}},
+		},
+	}
+}
+
+// TestFormatViolationsGolden pins the exact text fed back to the model for
+// representative failures. The format is deliberately frozen: models are
+// prompted against it, so changes here are breaking.
+func TestFormatViolationsGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{
+			name: "missing required fields",
+			args: `{"unit": "celsius"}`,
+			want: "- days: missing required field (expected integer)\n" +
+				"- location: missing required field (expected string)",
+		},
+		{
+			name: "wrong types",
+			args: `{"location": 42, "days": "three"}`,
+			want: "- days: expected integer, got string\n" +
+				"- location: expected string, got integer",
+		},
+		{
+			name: "missing and wrong mixed",
+			args: `{"days": 1.5}`,
+			want: "- days: expected integer, got number\n" +
+				"- location: missing required field (expected string)",
+		},
+		{
+			name: "non-object arguments",
+			args: `["location"]`,
+			want: "- (arguments): expected object, got array",
+		},
+		{
+			name: "integer accepted for number is not reported",
+			args: `{"location": "sf", "days": 2}`,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := ValidateToolArgs(json.RawMessage(tt.args), weatherDef(), false)
+			got := FormatViolations(vs)
+			if got != tt.want {
+				t.Errorf("FormatViolations() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFormatViolationsDeterministic ensures ordering does not depend on input
+// order.
+func TestFormatViolationsDeterministic(t *testing.T) {
+	vs := []Violation{
+		{Path: "b", Code: "wrong_type", Expected: "string", Actual: "integer"},
+		{Path: "a", Code: "missing_required", Expected: "string"},
+	}
+	rev := []Violation{vs[1], vs[0]}
+	if FormatViolations(vs) != FormatViolations(rev) {
+		t.Error("FormatViolations output depends on input order")
+	}
+}
+
+func TestValidateToolArgsNulls(t *testing.T) {
+	def := core.ToolDef{Parameters: []core.ToolParameter{
+		{Name: "location", Required: true, Schema: map[string]any{"type": "string"}},
+		{Name: "unit", Required: false, Nullable: true, Schema: map[string]any{"type": "string"}},
+	}}
+	args := json.RawMessage(`{"location": null, "unit": null}`)
+
+	if vs := ValidateToolArgs(args, def, false); len(vs) != 0 {
+		t.Errorf("nulls without strict mode should pass, got %v", vs)
+	}
+
+	vs := ValidateToolArgs(args, def, true)
+	if len(vs) != 1 {
+		t.Fatalf("strict mode: got %v, want exactly the required field flagged", vs)
+	}
+	want := "- location: null is not allowed (expected string)"
+	if got := FormatViolations(vs); got != want {
+		t.Errorf("FormatViolations() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateToolArgsNumberAcceptsInteger(t *testing.T) {
+	def := core.ToolDef{Parameters: []core.ToolParameter{
+		{Name: "ratio", Required: true, Schema: map[string]any{"type": "number"}},
+	}}
+	if vs := ValidateToolArgs(json.RawMessage(`{"ratio": 3}`), def, false); len(vs) != 0 {
+		t.Errorf("integer should satisfy number, got %v", vs)
+	}
+}
@@ -0,0 +1,103 @@
+package llmrouter
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// routerWithLogBuffer builds a test router whose logs land in the returned
+// buffer.
+func routerWithLogBuffer(t *testing.T, models map[string]config.ModelConfig, fake core.RawClient) (*Router, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r := newTestRouter(t, models, fake, WithLogger(logger))
+	return r, &buf
+}
+
+// TestRoutersLogToOwnHandlers constructs two routers with distinct slog
+// handlers and asserts each request's logs land only on its own handler.
+func TestRoutersLogToOwnHandlers(t *testing.T) {
+	var bufA, bufB bytes.Buffer
+	loggerA := slog.New(slog.NewTextHandler(&bufA, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	loggerB := slog.New(slog.NewTextHandler(&bufB, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	modelsA := map[string]config.ModelConfig{
+		"model-a": {Provider: "test", Model: "m-a", SupportsTools: true},
+	}
+	modelsB := map[string]config.ModelConfig{
+		"model-b": {Provider: "test", Model: "m-b", SupportsTools: true},
+	}
+	rA := newTestRouter(t, modelsA, &fakeClient{responses: []*core.RawResponse{textResponse("a")}}, WithLogger(loggerA))
+	rB := newTestRouter(t, modelsB, &fakeClient{responses: []*core.RawResponse{textResponse("b")}}, WithLogger(loggerB))
+
+	if _, err := rA.ExecuteRaw(context.Background(), Request{Model: "model-a", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("router A: %v", err)
+	}
+	if _, err := rB.ExecuteRaw(context.Background(), Request{Model: "model-b", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("router B: %v", err)
+	}
+
+	a, b := bufA.String(), bufB.String()
+	if !strings.Contains(a, "model-a") || strings.Contains(a, "model-b") {
+		t.Errorf("router A handler saw wrong logs:\n%s", a)
+	}
+	if !strings.Contains(b, "model-b") || strings.Contains(b, "model-a") {
+		t.Errorf("router B handler saw wrong logs:\n%s", b)
+	}
+}
+
+// recordingMetrics captures every observation for assertions.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	calls   []string
+	tools   []string
+	retries []int
+}
+
+func (m *recordingMetrics) ObserveCall(provider, modelKey string, usage Usage, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, provider+"/"+modelKey)
+}
+
+func (m *recordingMetrics) ObserveToolExecution(tool string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tools = append(m.tools, tool)
+}
+
+func (m *recordingMetrics) ObserveRetry(provider string, attempt int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries = append(m.retries, attempt)
+}
+
+func TestMetricsObservations(t *testing.T) {
+	metrics := &recordingMetrics{}
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake, WithMetrics(metrics))
+
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.calls) != 2 || metrics.calls[0] != "test/fake" {
+		t.Errorf("calls = %v", metrics.calls)
+	}
+	if len(metrics.tools) != 1 || metrics.tools[0] != "echo" {
+		t.Errorf("tools = %v", metrics.tools)
+	}
+}
@@ -0,0 +1,133 @@
+// Package mcp bridges Model Context Protocol servers into router tools:
+// ConnectMCP lists a server's tools and returns llmrouter.Tool
+// implementations whose argument schemas come from the server's JSON Schema
+// and whose Execute forwards calls over the MCP session.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	llm "github.com/LizzyG/llmrouter"
+)
+
+// Transport carries JSON-RPC requests to an MCP server; see StdioTransport
+// and HTTPTransport.
+type Transport interface {
+	// Call performs one JSON-RPC request and returns the result payload.
+	Call(ctx context.Context, method string, params any) (json.RawMessage, error)
+	// Notify sends a JSON-RPC notification (no response expected).
+	Notify(ctx context.Context, method string, params any) error
+	Close() error
+}
+
+// ConnectMCP performs the MCP initialize handshake over the transport,
+// lists the server's tools, and returns them as router tools. The caller
+// owns the transport's lifetime; closing it invalidates the tools.
+func ConnectMCP(ctx context.Context, t Transport) ([]llm.Tool, error) {
+	_, err := t.Call(ctx, "initialize", map[string]any{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "llmrouter", "version": "dev"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mcp: initialize: %w", err)
+	}
+	if err := t.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, fmt.Errorf("mcp: initialized notification: %w", err)
+	}
+
+	raw, err := t.Call(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("mcp: tools/list: %w", err)
+	}
+	var listed struct {
+		Tools []struct {
+			Name        string          `json:"name"`
+			Description string          `json:"description"`
+			InputSchema json.RawMessage `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &listed); err != nil {
+		return nil, fmt.Errorf("mcp: parsing tools/list: %w", err)
+	}
+
+	out := make([]llm.Tool, 0, len(listed.Tools))
+	for _, lt := range listed.Tools {
+		out = append(out, &mcpTool{
+			name:        lt.Name,
+			description: lt.Description,
+			schema:      string(lt.InputSchema),
+			transport:   t,
+		})
+	}
+	return out, nil
+}
+
+// mcpTool adapts one server-side tool. Its JSONSchema method routes the
+// server's schema verbatim into the tool definition instead of the
+// reflection path.
+type mcpTool struct {
+	name        string
+	description string
+	schema      string
+	transport   Transport
+}
+
+func (m *mcpTool) Name() string        { return m.name }
+func (m *mcpTool) Description() string { return m.description }
+
+// JSONSchema returns the server-declared input schema verbatim.
+func (m *mcpTool) JSONSchema() string { return m.schema }
+
+// Parameters is unused; MCP tools take the RawTool path. It exists to
+// satisfy the base Tool interface.
+func (m *mcpTool) Parameters() any { return &map[string]any{} }
+
+// Execute satisfies the base Tool interface by delegating to ExecuteRaw.
+func (m *mcpTool) Execute(ctx context.Context, args any) (any, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	return m.ExecuteRaw(ctx, b)
+}
+
+// ExecuteRaw forwards the untouched arguments over the MCP session and
+// returns the result content: plain text when the server replied with a
+// single text block, otherwise the structured content list.
+func (m *mcpTool) ExecuteRaw(ctx context.Context, args json.RawMessage) (any, error) {
+	arguments := json.RawMessage("{}")
+	if len(args) > 0 {
+		arguments = args
+	}
+	raw, err := m.transport.Call(ctx, "tools/call", map[string]any{
+		"name":      m.name,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mcp: tools/call %s: %w", m.name, err)
+	}
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("mcp: parsing tools/call result: %w", err)
+	}
+	if result.IsError {
+		msg := "tool reported an error"
+		if len(result.Content) > 0 {
+			msg = result.Content[0].Text
+		}
+		return nil, fmt.Errorf("mcp: %s: %s", m.name, msg)
+	}
+	if len(result.Content) == 1 && result.Content[0].Type == "text" {
+		return result.Content[0].Text, nil
+	}
+	return result.Content, nil
+}
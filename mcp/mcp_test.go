@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeMCPServer implements enough of the protocol over HTTP for the bridge.
+func fakeMCPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad request: %v", err)
+		}
+		if req.ID == nil { // notification
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "initialize":
+			result = `{"protocolVersion": "2024-11-05", "capabilities": {}, "serverInfo": {"name": "fake"}}`
+		case "tools/list":
+			result = `{"tools": [{
+				"name": "lookup_order",
+				"description": "Look up an order by ID",
+				"inputSchema": {"type": "object", "properties": {"order_id": {"type": "string"}}, "required": ["order_id"]}
+			}]}`
+		case "tools/call":
+			params, _ := json.Marshal(req.Params)
+			var call struct {
+				Name      string         `json:"name"`
+				Arguments map[string]any `json:"arguments"`
+			}
+			json.Unmarshal(params, &call)
+			if call.Name != "lookup_order" || call.Arguments["order_id"] != "A-1" {
+				t.Errorf("unexpected call: %+v", call)
+			}
+			result = `{"content": [{"type": "text", "text": "order A-1: shipped"}]}`
+		default:
+			t.Errorf("unexpected method %q", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"jsonrpc": "2.0", "id": `+itoa(*req.ID)+`, "result": `+result+`}`)
+	}))
+}
+
+func itoa(i int64) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+func TestConnectMCPAndExecute(t *testing.T) {
+	srv := fakeMCPServer(t)
+	defer srv.Close()
+
+	tools, err := ConnectMCP(context.Background(), NewHTTPTransport(srv.URL))
+	if err != nil {
+		t.Fatalf("ConnectMCP: %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("tools = %d", len(tools))
+	}
+	tool := tools[0]
+	if tool.Name() != "lookup_order" || tool.Description() == "" {
+		t.Errorf("tool = %s / %s", tool.Name(), tool.Description())
+	}
+
+	// The server's JSON Schema is carried verbatim.
+	rs, ok := tool.(interface{ JSONSchema() string })
+	if !ok {
+		t.Fatal("MCP tools must expose their raw schema")
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(rs.JSONSchema()), &schema); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	if _, ok := schema["properties"].(map[string]any)["order_id"]; !ok {
+		t.Errorf("schema = %v", schema)
+	}
+
+	// Execution forwards over the session.
+	args := &map[string]any{"order_id": "A-1"}
+	out, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if out != "order A-1: shipped" {
+		t.Errorf("out = %v", out)
+	}
+}
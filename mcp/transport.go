@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+)
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// StdioTransport speaks newline-delimited JSON-RPC over a subprocess's
+// stdin/stdout, the MCP stdio framing.
+type StdioTransport struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID atomic.Int64
+}
+
+// NewStdioTransport starts cmd and attaches to its pipes.
+func NewStdioTransport(cmd *exec.Cmd) (*StdioTransport, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp: starting server: %w", err)
+	}
+	return &StdioTransport{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// Call implements Transport. Requests are serialized: MCP stdio servers
+// answer in order.
+func (t *StdioTransport) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id := t.nextID.Add(1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.write(rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := t.stdout.ReadBytes('\n')
+		if err != nil {
+			return nil, fmt.Errorf("mcp: reading response: %w", err)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var resp rpcResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return nil, fmt.Errorf("mcp: parsing response: %w", err)
+		}
+		if resp.ID != id {
+			// Server-initiated message or stale response; skip.
+			continue
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Result, nil
+	}
+}
+
+// Notify implements Transport.
+func (t *StdioTransport) Notify(ctx context.Context, method string, params any) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.write(rpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (t *StdioTransport) write(req rpcRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = t.stdin.Write(append(b, '\n'))
+	return err
+}
+
+// Close shuts the server down.
+func (t *StdioTransport) Close() error {
+	t.stdin.Close()
+	return t.cmd.Wait()
+}
+
+// HTTPTransport speaks JSON-RPC over the MCP streamable HTTP transport:
+// each message is POSTed to the endpoint and answered in the response body.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+	nextID atomic.Int64
+}
+
+// NewHTTPTransport builds a transport against an MCP HTTP endpoint.
+func NewHTTPTransport(url string) *HTTPTransport {
+	return &HTTPTransport{url: url, client: &http.Client{}}
+}
+
+// Call implements Transport.
+func (t *HTTPTransport) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id := t.nextID.Add(1)
+	body, err := t.post(ctx, rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("mcp: parsing response: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+// Notify implements Transport.
+func (t *HTTPTransport) Notify(ctx context.Context, method string, params any) error {
+	_, err := t.post(ctx, rpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+	return err
+}
+
+func (t *HTTPTransport) post(ctx context.Context, req rpcRequest) ([]byte, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: http: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("mcp: http status %d: %s", resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Close implements Transport; HTTP transports hold no persistent state.
+func (t *HTTPTransport) Close() error { return nil }
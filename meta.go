@@ -0,0 +1,70 @@
+package llmrouter
+
+import (
+	"context"
+	"time"
+)
+
+// ResponseMeta describes how a request was served: which model answered,
+// what it cost in tokens across every turn of the tool loop, and how long
+// each turn took. It is returned by the WithMeta variants so callers can
+// bill and monitor without scraping logs.
+type ResponseMeta struct {
+	// ModelKey is the config key of the model that served the request,
+	// after auto-selection and web-variant resolution.
+	ModelKey string
+	// Provider is the resolved model's provider name.
+	Provider string
+	// Usage is aggregated across every model turn of the tool loop.
+	Usage Usage
+	// ToolTurns is how many turns requested tool executions.
+	ToolTurns int
+	// TurnLatencies holds the provider-call latency of each model turn, in
+	// order.
+	TurnLatencies []time.Duration
+	// TurnUsage holds each model turn's individual token usage, in order.
+	TurnUsage []Usage
+	// TurnTimings breaks each turn's latency into TTFB vs total (and TTFT
+	// when streamed), separating network/queueing from generation time.
+	TurnTimings []Timing
+	// FinishReason reports why the final turn stopped generating.
+	FinishReason FinishReason
+	// Truncated is set when the final answer was cut off by the token
+	// limit; raising Request.MaxTokens will usually fix it.
+	Truncated bool
+	// SkippedTools names tools omitted under SkipBrokenTools because their
+	// parameters could not be reflected.
+	SkippedTools []string
+	// Cost is the dollar cost of the request computed from the model's
+	// configured per-token prices, summed across every turn of the tool
+	// loop. Zero when the model has no pricing configured.
+	Cost float64
+	// RequestID is the identifier every log line of this execution carried
+	// and that was forwarded to the provider where supported.
+	RequestID string
+	// Transcript is the full executed conversation — request messages,
+	// every assistant tool call and tool result with call IDs, and the
+	// final answer. Populated only when Request.CaptureTranscript is set;
+	// feed it back into Request.Messages to resume the run.
+	Transcript []Message
+}
+
+// ExecuteRawWithMeta behaves like ExecuteRaw and additionally returns
+// response metadata.
+func (r *Router) ExecuteRawWithMeta(ctx context.Context, req Request) (string, ResponseMeta, error) {
+	res, err := r.executeInternal(ctx, req, "")
+	if err != nil {
+		return "", ResponseMeta{}, err
+	}
+	return res.content, res.meta, nil
+}
+
+// ExecuteWithMeta behaves like Execute and additionally returns response
+// metadata.
+func ExecuteWithMeta[T any](ctx context.Context, r *Router, req Request) (T, ResponseMeta, error) {
+	out, res, err := executeTyped[T](ctx, r, req)
+	if res == nil {
+		return out, ResponseMeta{}, err
+	}
+	return out, res.meta, err
+}
@@ -0,0 +1,126 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestExecuteRawWithMetaAggregatesToolLoop(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "a"}`),
+		toolCallResponse("echo", `{"text": "b"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	out, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExecuteRawWithMeta: %v", err)
+	}
+	if out != "done" {
+		t.Errorf("content = %q", out)
+	}
+	if meta.ModelKey != "fake" || meta.Provider != "test" {
+		t.Errorf("resolved model = %s/%s", meta.Provider, meta.ModelKey)
+	}
+	// Three model turns at 10/5/15 tokens each.
+	if meta.Usage.PromptTokens != 30 || meta.Usage.CompletionTokens != 15 || meta.Usage.TotalTokens != 45 {
+		t.Errorf("usage = %+v, want sums across all turns", meta.Usage)
+	}
+	if meta.ToolTurns != 2 {
+		t.Errorf("ToolTurns = %d, want 2", meta.ToolTurns)
+	}
+	if len(meta.TurnLatencies) != 3 {
+		t.Errorf("TurnLatencies = %v, want one per model turn", meta.TurnLatencies)
+	}
+}
+
+func TestTruncationDetection(t *testing.T) {
+	truncated := &core.RawResponse{
+		Content:      `{"items": ["one", "tw`,
+		FinishReason: core.FinishLength,
+		Usage:        core.Usage{PromptTokens: 10, CompletionTokens: 100, TotalTokens: 110},
+	}
+	r := newTestRouter(t, testModels(), &fakeClient{responses: []*core.RawResponse{truncated}})
+
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), userRequest("list"))
+	if err != nil {
+		t.Fatalf("ExecuteRawWithMeta: %v", err)
+	}
+	if meta.FinishReason != FinishLength || !meta.Truncated {
+		t.Errorf("meta = %+v, want truncation flagged", meta)
+	}
+
+	// The typed path can't parse the cut-off JSON and must say why.
+	r = newTestRouter(t, testModels(), &fakeClient{responses: []*core.RawResponse{truncated}})
+	_, _, err = ExecuteWithMeta[itemList](context.Background(), r, userRequest("list"))
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("err = %v, want ErrTruncated", err)
+	}
+}
+
+func TestExecuteWithMeta(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items": ["x"]}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	out, meta, err := ExecuteWithMeta[itemList](context.Background(), r, userRequest("list"))
+	if err != nil {
+		t.Fatalf("ExecuteWithMeta: %v", err)
+	}
+	if len(out.Items) != 1 || out.Items[0] != "x" {
+		t.Errorf("value = %+v", out)
+	}
+	if meta.Usage.TotalTokens != 15 || meta.ModelKey != "fake" {
+		t.Errorf("meta = %+v", meta)
+	}
+}
+
+func TestCaptureTranscript(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"a"}`),
+		textResponse("final"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.CaptureTranscript = true
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// user, assistant tool call, tool result, final answer.
+	if len(meta.Transcript) != 4 {
+		t.Fatalf("transcript = %d messages: %+v", len(meta.Transcript), meta.Transcript)
+	}
+	if len(meta.Transcript[1].ToolCalls) != 1 || meta.Transcript[1].ToolCalls[0].CallID != "call_1" {
+		t.Errorf("tool call entry = %+v", meta.Transcript[1])
+	}
+	if len(meta.Transcript[2].ToolResults) != 1 || meta.Transcript[2].ToolResults[0].CallID != "call_1" {
+		t.Errorf("tool result entry = %+v", meta.Transcript[2])
+	}
+	if meta.Transcript[3].Content != "final" {
+		t.Errorf("final entry = %+v", meta.Transcript[3])
+	}
+	if len(meta.TurnUsage) != 2 || meta.TurnUsage[0].TotalTokens != 15 {
+		t.Errorf("TurnUsage = %+v", meta.TurnUsage)
+	}
+
+	// Off by default.
+	fake2 := &fakeClient{responses: []*core.RawResponse{textResponse("x")}}
+	r2 := newTestRouter(t, testModels(), fake2)
+	_, meta2, err := r2.ExecuteRawWithMeta(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta2.Transcript != nil {
+		t.Error("transcript captured without the flag")
+	}
+}
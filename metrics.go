@@ -0,0 +1,22 @@
+package llmrouter
+
+import "time"
+
+// Metrics receives instrumentation callbacks so applications can export
+// token counts, latencies, and retries to their metrics backend without
+// scraping logs. Implementations must be safe for concurrent use; a nil
+// registration adds zero overhead.
+type Metrics interface {
+	// ObserveCall fires after every provider call, successful or not.
+	ObserveCall(provider, modelKey string, usage Usage, latency time.Duration, err error)
+	// ObserveToolExecution fires after every tool execution.
+	ObserveToolExecution(tool string, latency time.Duration, err error)
+	// ObserveRetry fires each time a provider call is retried after a
+	// transient failure; attempt is the attempt number that just failed.
+	ObserveRetry(provider string, attempt int)
+}
+
+// WithMetrics registers a metrics sink on the router.
+func WithMetrics(m Metrics) Option {
+	return func(r *Router) { r.metrics = m }
+}
@@ -0,0 +1,77 @@
+package llmrouter
+
+import "sort"
+
+// ModelInfo is a read-only view of one configured model, for building model
+// pickers and capability-aware UIs. It never exposes API keys.
+type ModelInfo struct {
+	// Key is the config key requests use.
+	Key      string
+	Provider string
+	// Model is the provider's underlying model name.
+	Model string
+
+	SupportsTools            bool
+	SupportsStructuredOutput bool
+	SupportsWebSearch        bool
+	SupportsFiles            bool
+	SupportsAudio            bool
+	Tags                     []string
+
+	ContextWindow   int
+	MaxOutputTokens int
+	Deprecated      bool
+
+	InputCostPerMTok       float64
+	OutputCostPerMTok      float64
+	CachedInputCostPerMTok float64
+
+	// Split is set on virtual traffic-splitting entries, which have no
+	// provider or model of their own; requests against the key route to
+	// the listed members by weight.
+	Split map[string]int
+}
+
+// Models lists the configured models, sorted by key.
+func (r *Router) Models() []ModelInfo {
+	out := make([]ModelInfo, 0, len(r.snapshot().models))
+	for key, mc := range r.snapshot().models {
+		tags := make([]string, len(mc.Tags))
+		copy(tags, mc.Tags)
+		split := make(map[string]int, len(mc.Split))
+		for member, weight := range mc.Split {
+			split[member] = weight
+		}
+		if len(split) == 0 {
+			split = nil
+		}
+		out = append(out, ModelInfo{
+			Key:                      key,
+			Split:                    split,
+			Provider:                 mc.Provider,
+			Model:                    mc.Model,
+			SupportsTools:            mc.SupportsTools,
+			SupportsStructuredOutput: mc.SupportsStructuredOutput,
+			SupportsWebSearch:        mc.SupportsWebSearch,
+			SupportsFiles:            mc.SupportsFiles,
+			SupportsAudio:            mc.SupportsAudio,
+			Tags:                     tags,
+			ContextWindow:            mc.ContextWindow,
+			MaxOutputTokens:          mc.MaxOutputTokens,
+			Deprecated:               mc.Deprecated,
+			InputCostPerMTok:         mc.InputCostPerMTok,
+			OutputCostPerMTok:        mc.OutputCostPerMTok,
+			CachedInputCostPerMTok:   mc.CachedInputCostPerMTok,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ModelFor previews which model key a request would be routed to, running
+// the full selection logic (default model, aliases, capability filters,
+// web variants) without executing anything.
+func (r *Router) ModelFor(req Request) (string, error) {
+	key, _, err := r.selectModel(req)
+	return key, err
+}
@@ -0,0 +1,58 @@
+package llmrouter
+
+import (
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+func TestModelsIntrospection(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"zeta":  {Provider: "openai", Model: "gpt-4o", APIKey: "sk-secret", SupportsTools: true, Tags: []string{"vision"}},
+		"alpha": {Provider: "gemini", Model: "gemini-2.0-flash", APIKey: "g-secret", ContextWindow: 1000000},
+	}
+	r := newTestRouter(t, models, &fakeClient{})
+
+	infos := r.Models()
+	if len(infos) != 2 || infos[0].Key != "alpha" || infos[1].Key != "zeta" {
+		t.Fatalf("Models() = %+v, want sorted by key", infos)
+	}
+	if infos[1].Model != "gpt-4o" || !infos[1].SupportsTools || infos[1].Tags[0] != "vision" {
+		t.Errorf("zeta info = %+v", infos[1])
+	}
+	if infos[0].ContextWindow != 1000000 {
+		t.Errorf("alpha info = %+v", infos[0])
+	}
+}
+
+func TestModelForPreviewsSelection(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-plain": {Provider: "test", Model: "a"},
+		"bbb-tools": {Provider: "test", Model: "b", SupportsTools: true},
+	}
+	fake := &fakeClient{}
+	r := newTestRouter(t, models, fake)
+
+	key, err := r.ModelFor(Request{Tools: []Tool{&echoTool{}}})
+	if err != nil || key != "bbb-tools" {
+		t.Errorf("ModelFor = %q, %v", key, err)
+	}
+	if len(fake.calls) != 0 {
+		t.Error("ModelFor must not execute anything")
+	}
+	if _, err := r.ModelFor(Request{Model: "missing"}); err == nil {
+		t.Error("unknown model should error")
+	}
+}
+
+func TestModelsExposesPricing(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"priced": {Provider: "openai", Model: "gpt-4o", APIKey: "k",
+			InputCostPerMTok: 2.5, OutputCostPerMTok: 10, CachedInputCostPerMTok: 1.25},
+	}
+	r := newTestRouter(t, models, &fakeClient{})
+	info := r.Models()[0]
+	if info.InputCostPerMTok != 2.5 || info.OutputCostPerMTok != 10 || info.CachedInputCostPerMTok != 1.25 {
+		t.Errorf("info = %+v", info)
+	}
+}
@@ -0,0 +1,65 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// Candidate is one alternative completion with its own finish reason.
+type Candidate = core.Candidate
+
+// ExecuteRawMulti runs the request and returns every candidate the model
+// produced for the final turn (set Request.CandidateCount to ask for more
+// than one). Usage in the metadata reflects the combined completion tokens
+// of all candidates; the tool loop, when tools are involved, only follows
+// the first.
+func (r *Router) ExecuteRawMulti(ctx context.Context, req Request) ([]Candidate, ResponseMeta, error) {
+	res, err := r.executeInternal(ctx, req, "")
+	if err != nil {
+		return nil, ResponseMeta{}, err
+	}
+	return res.allCandidates(), res.meta, nil
+}
+
+// ExecuteMulti is the typed variant of ExecuteRawMulti: each candidate is
+// parsed into T through the same repair path Execute uses. Parsing failures
+// are reported with the candidate's index.
+func ExecuteMulti[T any](ctx context.Context, r *Router, req Request) ([]T, ResponseMeta, error) {
+	var zero T
+	schema, err := util.CachedResponseSchema(zero)
+	if err != nil {
+		return nil, ResponseMeta{}, fmt.Errorf("llmrouter: generating schema: %w", err)
+	}
+	req.requireStructured = true
+	res, err := r.executeInternal(ctx, req, schema)
+	if err != nil {
+		return nil, ResponseMeta{}, err
+	}
+
+	candidates := res.allCandidates()
+	out := make([]T, 0, len(candidates))
+	for i, c := range candidates {
+		var v T
+		if uerr := json.Unmarshal([]byte(c.Content), &v); uerr != nil {
+			repaired := util.RepairJSON(c.Content)
+			if uerr = json.Unmarshal([]byte(repaired), &v); uerr != nil {
+				return nil, res.meta, fmt.Errorf("%w: candidate %d: %s", ErrStructuredOutput, i, describeUnmarshalError(uerr))
+			}
+		}
+		out = append(out, v)
+	}
+	return out, res.meta, nil
+}
+
+// allCandidates returns the final turn's candidates, falling back to the
+// single content for providers (or fakes) that don't enumerate them.
+func (res *execResult) allCandidates() []Candidate {
+	if len(res.candidates) > 0 {
+		return res.candidates
+	}
+	return []Candidate{{Content: res.content, FinishReason: res.meta.FinishReason}}
+}
@@ -0,0 +1,70 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestExecuteRawMulti(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{{
+		Content: "first",
+		Candidates: []core.Candidate{
+			{Content: "first", FinishReason: core.FinishStop},
+			{Content: "second", FinishReason: core.FinishLength},
+		},
+		Usage: core.Usage{PromptTokens: 5, CompletionTokens: 20, TotalTokens: 25},
+	}}}
+	r := newTestRouter(t, testModels(), fake)
+
+	req := userRequest("alternatives please")
+	req.CandidateCount = 2
+	cands, meta, err := r.ExecuteRawMulti(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.calls[0].CandidateCount != 2 {
+		t.Errorf("CandidateCount not plumbed: %d", fake.calls[0].CandidateCount)
+	}
+	if len(cands) != 2 || cands[1].Content != "second" || cands[1].FinishReason != FinishLength {
+		t.Errorf("candidates = %+v", cands)
+	}
+	if meta.Usage.CompletionTokens != 20 {
+		t.Errorf("usage = %+v", meta.Usage)
+	}
+}
+
+func TestExecuteMultiTyped(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{{
+		Content: `{"items":["a"]}`,
+		Candidates: []core.Candidate{
+			{Content: `{"items":["a"]}`},
+			{Content: "```json\n{\"items\":[\"b\"]}\n```"},
+		},
+		Usage: core.Usage{TotalTokens: 10},
+	}}}
+	r := newTestRouter(t, testModels(), fake)
+
+	req := userRequest("lists")
+	req.CandidateCount = 2
+	lists, _, err := ExecuteMulti[itemList](context.Background(), r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 2 || lists[0].Items[0] != "a" || lists[1].Items[0] != "b" {
+		t.Errorf("lists = %+v", lists)
+	}
+}
+
+func TestExecuteRawMultiSingleCandidateFallback(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("only")}}
+	r := newTestRouter(t, testModels(), fake)
+	cands, _, err := r.ExecuteRawMulti(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cands) != 1 || cands[0].Content != "only" {
+		t.Errorf("candidates = %+v", cands)
+	}
+}
@@ -0,0 +1,72 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// NewTool builds a Tool from a typed function, deriving the parameter
+// schema from Args by reflection and handling the type assertion callers
+// previously wrote by hand in Execute.
+func NewTool[Args any](name, description string, fn func(ctx context.Context, args Args) (any, error)) Tool {
+	return &funcTool[Args]{name: name, description: description, fn: fn}
+}
+
+// FieldSpec overrides schema details for one parameter of a function-based
+// tool, so shared argument types don't need struct tags.
+type FieldSpec struct {
+	// Description replaces the field's description tag when non-empty.
+	Description string
+	// Required overrides the reflected requiredness when non-nil.
+	Required *bool
+}
+
+// NewToolWithFields is NewTool with per-field description and requiredness
+// overrides, keyed by the parameter's JSON name.
+func NewToolWithFields[Args any](name, description string, fields map[string]FieldSpec, fn func(ctx context.Context, args Args) (any, error)) Tool {
+	return &funcTool[Args]{name: name, description: description, fn: fn, fields: fields}
+}
+
+type funcTool[Args any] struct {
+	name        string
+	description string
+	fn          func(ctx context.Context, args Args) (any, error)
+	fields      map[string]FieldSpec
+}
+
+func (t *funcTool[Args]) Name() string        { return t.name }
+func (t *funcTool[Args]) Description() string { return t.description }
+func (t *funcTool[Args]) Parameters() any     { return new(Args) }
+
+func (t *funcTool[Args]) Execute(ctx context.Context, args any) (any, error) {
+	typed, ok := args.(*Args)
+	if !ok {
+		return nil, fmt.Errorf("llmrouter: tool %s received %T, want %T", t.name, args, new(Args))
+	}
+	return t.fn(ctx, *typed)
+}
+
+// applyOverrides merges the field specs into the reflected parameters; the
+// router consults it while building tool definitions.
+func (t *funcTool[Args]) applyOverrides(params []core.ToolParameter) []core.ToolParameter {
+	if len(t.fields) == 0 {
+		return params
+	}
+	out := make([]core.ToolParameter, len(params))
+	copy(out, params)
+	for i := range out {
+		spec, ok := t.fields[out[i].Name]
+		if !ok {
+			continue
+		}
+		if spec.Description != "" {
+			out[i].Description = spec.Description
+		}
+		if spec.Required != nil {
+			out[i].Required = *spec.Required
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+type weatherArgs struct {
+	Location string `json:"location" description:"city name"`
+	Days     int    `json:"days,omitempty"`
+}
+
+func TestNewToolRoundTrip(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("get_weather", `{"location": "Paris", "days": 3}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	var got weatherArgs
+	tool := NewTool("get_weather", "Get the forecast", func(ctx context.Context, args weatherArgs) (any, error) {
+		got = args
+		return map[string]string{"forecast": "sunny"}, nil
+	})
+
+	req := userRequest("weather?")
+	req.Tools = []Tool{tool}
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if got.Location != "Paris" || got.Days != 3 {
+		t.Errorf("args = %+v", got)
+	}
+
+	defs := fake.calls[0].ToolDefs
+	if len(defs) != 1 || defs[0].Name != "get_weather" {
+		t.Fatalf("defs = %+v", defs)
+	}
+	byName := map[string]core.ToolParameter{}
+	for _, p := range defs[0].Parameters {
+		byName[p.Name] = p
+	}
+	if byName["location"].Description != "city name" || !byName["location"].Required {
+		t.Errorf("location param = %+v", byName["location"])
+	}
+	if byName["days"].Required {
+		t.Error("omitempty field should be optional")
+	}
+}
+
+func TestNewToolWithFieldsOverrides(t *testing.T) {
+	type sharedArgs struct {
+		Query  string `json:"query"`
+		Window int    `json:"window"`
+	}
+	optional := false
+	tool := NewToolWithFields("search", "Searches",
+		map[string]FieldSpec{
+			"query":  {Description: "full-text query"},
+			"window": {Required: &optional},
+		},
+		func(ctx context.Context, args sharedArgs) (any, error) { return nil, nil })
+
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]core.ToolParameter{}
+	for _, p := range fake.calls[0].ToolDefs[0].Parameters {
+		byName[p.Name] = p
+	}
+	if byName["query"].Description != "full-text query" {
+		t.Errorf("query = %+v", byName["query"])
+	}
+	if byName["window"].Required {
+		t.Error("override should make window optional")
+	}
+}
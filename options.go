@@ -0,0 +1,95 @@
+package llmrouter
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Option configures a Router at construction time.
+type Option func(*Router)
+
+// WithLogger sets the logger for router and provider logging. The default is
+// slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(r *Router) { r.logger = l }
+}
+
+// WithPayloadRecorder installs a callback receiving redacted copies of the
+// exact request and response payloads exchanged with each provider, plus
+// the HTTP status (0 when the request never completed). API keys are
+// stripped before delivery. This is the programmatic replacement for the
+// LLM_VERBOSE_MESSAGES environment switch.
+func WithPayloadRecorder(fn func(provider string, req, resp []byte, status int)) Option {
+	return func(r *Router) { r.recorder = fn }
+}
+
+// WithFailover makes transient mid-tool-loop failures resume on an
+// alternative model by replaying the accumulated conversation — tool calls
+// and results included, with call IDs neutralized — instead of starting the
+// request over. The fallback chain is used when configured; otherwise any
+// other configured model that could serve the request is tried in
+// deterministic order.
+func WithFailover(enabled bool) Option {
+	return func(r *Router) { r.failover = enabled }
+}
+
+// SelectionStrategy orders the candidates considered during automatic
+// model selection.
+type SelectionStrategy int
+
+const (
+	// AlphabeticalFirst picks the alphabetically first capable model — the
+	// historical default.
+	AlphabeticalFirst SelectionStrategy = iota
+	// CheapestFirst sorts capable models by configured cost per token,
+	// cheapest first, with alphabetical order breaking ties so selection
+	// stays deterministic.
+	CheapestFirst
+)
+
+// WithSelectionStrategy changes how auto-selection orders candidate models
+// when Request.Model is empty. The default is AlphabeticalFirst.
+func WithSelectionStrategy(s SelectionStrategy) Option {
+	return func(r *Router) { r.selection = s }
+}
+
+// WithMaxConcurrentCalls caps how many provider HTTP calls may be in
+// flight across the whole router (tool-loop turns included). Callers block,
+// respecting their context, when the limit is saturated.
+func WithMaxConcurrentCalls(n int) Option {
+	return func(r *Router) {
+		if n > 0 {
+			r.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithStrictStructuredSupport makes typed requests against an explicitly
+// chosen model that lacks supports_structured_output fail with a typed
+// error instead of logging a warning and relying on JSON repair.
+func WithStrictStructuredSupport(strict bool) Option {
+	return func(r *Router) { r.strictStructured = strict }
+}
+
+// WithToolHook installs hooks around every tool execution. The pre hook
+// may derive a new context (e.g. to attach tenant metadata visible to the
+// tool) or return an error, which is handled according to the request's
+// tool-error policy. The post hook observes the exact result object that
+// will be serialized back to the model, along with the execution error, if
+// any. Either hook may be nil.
+func WithToolHook(pre func(ctx context.Context, info ToolCallInfo) (context.Context, error), post func(ctx context.Context, info ToolCallInfo, result any, err error)) Option {
+	return func(r *Router) {
+		r.preToolHook = pre
+		r.postToolHook = post
+	}
+}
+
+// WithMaxToolTurns caps how many model turns the tool loop may take before
+// giving up with ErrToolLoopLimit. The default is 8.
+func WithMaxToolTurns(n int) Option {
+	return func(r *Router) {
+		if n > 0 {
+			r.maxToolTurns = n
+		}
+	}
+}
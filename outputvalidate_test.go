@@ -0,0 +1,96 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+type report struct {
+	Name   string `json:"name"`
+	Status string `json:"status" enum:"open,closed"`
+}
+
+func TestValidateOutputCatchesZeroValueAbsorption(t *testing.T) {
+	// "status" missing: unmarshal succeeds with a zero value, but
+	// validation flags it.
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`{"name": "x"}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("extract")
+	req.ValidateOutput = true
+	_, err := Execute[report](context.Background(), r, req)
+	if !errors.Is(err, ErrOutputValidation) || !strings.Contains(err.Error(), "status: missing required") {
+		t.Errorf("err = %v", err)
+	}
+
+	// Bad enum value.
+	fake = &fakeClient{responses: []*core.RawResponse{textResponse(`{"name": "x", "status": "pending"}`)}}
+	r = newTestRouter(t, testModels(), fake)
+	_, err = Execute[report](context.Background(), r, req)
+	if !errors.Is(err, ErrOutputValidation) || !strings.Contains(err.Error(), "pending") {
+		t.Errorf("enum err = %v", err)
+	}
+
+	// Conforming output passes.
+	fake = &fakeClient{responses: []*core.RawResponse{textResponse(`{"name": "x", "status": "open"}`)}}
+	r = newTestRouter(t, testModels(), fake)
+	out, err := Execute[report](context.Background(), r, req)
+	if err != nil || out.Status != "open" {
+		t.Errorf("out=%+v err=%v", out, err)
+	}
+
+	// Without the flag, the zero-value absorption is unchanged.
+	fake = &fakeClient{responses: []*core.RawResponse{textResponse(`{"name": "x"}`)}}
+	r = newTestRouter(t, testModels(), fake)
+	req.ValidateOutput = false
+	if _, err := Execute[report](context.Background(), r, req); err != nil {
+		t.Errorf("legacy behavior changed: %v", err)
+	}
+}
+
+func TestValidateOutputStrictUnknownFields(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"name": "x", "status": "open", "invented": 1}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("extract")
+	req.ValidateOutput = true
+	req.StrictToolArgs = true
+	_, err := Execute[report](context.Background(), r, req)
+	if !errors.Is(err, ErrOutputValidation) || !strings.Contains(err.Error(), "invented: unknown field") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestValidateOutputAcceptsNullForPointerFields(t *testing.T) {
+	type annotated struct {
+		Name string  `json:"name"`
+		Note *string `json:"note"`
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"name": "x", "note": null}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("extract")
+	req.ValidateOutput = true
+	out, err := Execute[annotated](context.Background(), r, req)
+	if err != nil {
+		t.Fatalf("explicit null for an optional pointer field must validate: %v", err)
+	}
+	if out.Note != nil {
+		t.Errorf("out = %+v", out)
+	}
+
+	// A null for a required non-pointer field is still a violation.
+	fake = &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"name": null}`),
+	}}
+	r = newTestRouter(t, testModels(), fake)
+	_, err = Execute[annotated](context.Background(), r, req)
+	if !errors.Is(err, ErrOutputValidation) {
+		t.Errorf("err = %v, want null_not_allowed violation", err)
+	}
+}
@@ -0,0 +1,55 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// Ping verifies that one configured model is reachable with valid
+// credentials, using the cheapest call the provider offers. Failures are
+// mapped to ErrAuthFailed, ErrModelNotFound, or a transport error; there is
+// no retry loop.
+func (r *Router) Ping(ctx context.Context, modelKey string) error {
+	key, err := r.resolveAlias(modelKey)
+	if err != nil {
+		return err
+	}
+	cfg, ok := r.snapshot().models[key]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownModel, modelKey)
+	}
+	// Virtual split entries have nothing to ping; their members are
+	// checked under their own keys.
+	if len(cfg.Split) > 0 {
+		return nil
+	}
+	client, err := r.getClient(cfg)
+	if err != nil {
+		return err
+	}
+	p, ok := client.(core.Pinger)
+	if !ok {
+		return nil
+	}
+	return p.Ping(ctx, cfg.Model)
+}
+
+// PingAll pings every configured model and reports each failure by key;
+// healthy models are omitted from the map.
+func (r *Router) PingAll(ctx context.Context) map[string]error {
+	out := map[string]error{}
+	keys := make([]string, 0, len(r.snapshot().models))
+	for k := range r.snapshot().models {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := r.Ping(ctx, k); err != nil {
+			out[k] = err
+		}
+	}
+	return out
+}
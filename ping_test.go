@@ -0,0 +1,57 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+func TestPingMapsProviderFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/models/good-model"):
+			w.WriteHeader(http.StatusOK)
+		case strings.HasSuffix(r.URL.Path, "/models/gone-model"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"good":   {Provider: "openai", Model: "good-model", APIKey: "k", BaseURL: srv.URL},
+		"gone":   {Provider: "openai", Model: "gone-model", APIKey: "k", BaseURL: srv.URL},
+		"noauth": {Provider: "openai", Model: "secret-model", APIKey: "bad", BaseURL: srv.URL},
+	}}
+	r, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Ping(context.Background(), "good"); err != nil {
+		t.Errorf("good model: %v", err)
+	}
+	if err := r.Ping(context.Background(), "gone"); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("gone model err = %v, want ErrModelNotFound", err)
+	}
+	if err := r.Ping(context.Background(), "noauth"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("noauth err = %v, want ErrAuthFailed", err)
+	}
+	if err := r.Ping(context.Background(), "missing-key"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("missing key err = %v", err)
+	}
+
+	failures := r.PingAll(context.Background())
+	if len(failures) != 2 {
+		t.Errorf("PingAll = %v, want exactly the two broken models", failures)
+	}
+	if _, ok := failures["good"]; ok {
+		t.Error("healthy model reported by PingAll")
+	}
+}
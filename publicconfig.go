@@ -0,0 +1,128 @@
+package llmrouter
+
+import (
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+// Models declares a router's model map in code, for applications that
+// generate configuration from their own control plane instead of a YAML
+// file.
+type Models map[string]ModelConfig
+
+// ModelConfig is the public mirror of a config-file model entry; see the
+// config.yaml documentation for field semantics.
+type ModelConfig struct {
+	Provider string
+	Model    string
+	APIKey   string
+	APIKeys  []string
+	BaseURL  string
+
+	SupportsTools            bool
+	SupportsStructuredOutput bool
+	SupportsJSONSchema       bool
+	SupportsWebSearch        bool
+	SupportsFiles            bool
+	SupportsAudio            bool
+	WebVariant               string
+
+	Headers map[string]string
+
+	AllowedTools []string
+	DeniedTools  []string
+	Tags         []string
+
+	ContextWindow   int
+	MaxOutputTokens int
+
+	// Timeout bounds each provider call for this model.
+	Timeout time.Duration
+
+	// DefaultTemperature and friends are generation defaults applied when
+	// the request leaves the field unset; nil leaves the provider default.
+	DefaultTemperature *float64
+	DefaultTopP        *float64
+	DefaultTopK        *int
+	DefaultMaxTokens   *int
+
+	RPMLimit int
+	TPMLimit int
+
+	InputCostPerMTok       float64
+	OutputCostPerMTok      float64
+	CachedInputCostPerMTok float64
+
+	Fallbacks []string
+
+	Deprecated  bool
+	SunsetDate  string
+	AllowSunset bool
+	ReplacedBy  string
+	Split       map[string]int
+}
+
+// New builds a Router entirely from code: no config file, no process-wide
+// state. Aliases and a default model can be supplied through options built
+// on the same Request semantics (Request.Model accepts any declared key).
+func New(models Models, opts ...Option) (*Router, error) {
+	cfg := &config.LLMConfig{Models: make(map[string]config.ModelConfig, len(models))}
+	for key, mc := range models {
+		cfg.Models[key] = mc.toInternal()
+	}
+	if len(models) > 0 {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return NewRouter(cfg, opts...)
+}
+
+func (mc ModelConfig) generationDefaults() *config.GenerationDefaults {
+	if mc.DefaultTemperature == nil && mc.DefaultTopP == nil && mc.DefaultTopK == nil && mc.DefaultMaxTokens == nil {
+		return nil
+	}
+	return &config.GenerationDefaults{
+		Temperature: mc.DefaultTemperature,
+		TopP:        mc.DefaultTopP,
+		TopK:        mc.DefaultTopK,
+		MaxTokens:   mc.DefaultMaxTokens,
+	}
+}
+
+func (mc ModelConfig) toInternal() config.ModelConfig {
+	return config.ModelConfig{
+		Provider:                 mc.Provider,
+		Model:                    mc.Model,
+		APIKey:                   mc.APIKey,
+		APIKeys:                  mc.APIKeys,
+		BaseURL:                  mc.BaseURL,
+		SupportsTools:            mc.SupportsTools,
+		SupportsStructuredOutput: mc.SupportsStructuredOutput,
+		SupportsJSONSchema:       mc.SupportsJSONSchema,
+		SupportsWebSearch:        mc.SupportsWebSearch,
+		SupportsFiles:            mc.SupportsFiles,
+		SupportsAudio:            mc.SupportsAudio,
+		WebVariant:               mc.WebVariant,
+		Headers:                  mc.Headers,
+		AllowedTools:             mc.AllowedTools,
+		DeniedTools:              mc.DeniedTools,
+		Tags:                     mc.Tags,
+		ContextWindow:            mc.ContextWindow,
+		MaxOutputTokens:          mc.MaxOutputTokens,
+		Timeout:                  config.Duration(mc.Timeout),
+		Defaults:                 mc.generationDefaults(),
+		RPMLimit:                 mc.RPMLimit,
+		TPMLimit:                 mc.TPMLimit,
+		InputCostPerMTok:         mc.InputCostPerMTok,
+		OutputCostPerMTok:        mc.OutputCostPerMTok,
+		CachedInputCostPerMTok:   mc.CachedInputCostPerMTok,
+		Fallbacks:                mc.Fallbacks,
+		Deprecated:               mc.Deprecated,
+		SunsetDate:               mc.SunsetDate,
+		AllowSunset:              mc.AllowSunset,
+		ReplacedBy:               mc.ReplacedBy,
+		Split:                    mc.Split,
+	}
+}
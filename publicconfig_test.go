@@ -0,0 +1,63 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewFromCode(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+
+	r, err := New(Models{
+		"gpt4o": {
+			Provider:      "openai",
+			Model:         "gpt-4o",
+			APIKey:        "key",
+			BaseURL:       srv.URL,
+			SupportsTools: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.ExecuteRaw(context.Background(), Request{
+		Model:    "gpt4o",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil || out != "hello" {
+		t.Errorf("out=%q err=%v", out, err)
+	}
+	infos := r.Models()
+	if len(infos) != 1 || infos[0].Key != "gpt4o" || !infos[0].SupportsTools {
+		t.Errorf("Models() = %+v", infos)
+	}
+}
+
+func TestNewRejectsEmptyModels(t *testing.T) {
+	if _, err := New(Models{}); err == nil {
+		t.Error("empty model map should fail")
+	}
+}
+
+func TestNewFromConfigBytes(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+	yaml := `
+llm:
+  models:
+    gpt:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+      base_url: ` + srv.URL + `
+`
+	r, err := NewFromConfigBytes([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.ExecuteRaw(context.Background(), Request{Model: "gpt", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil || out != "hello" {
+		t.Errorf("out=%q err=%v", out, err)
+	}
+}
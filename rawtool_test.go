@@ -0,0 +1,54 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// proxyTool forwards raw args without schema reflection.
+type proxyTool struct {
+	gotRaw json.RawMessage
+}
+
+func (p *proxyTool) Name() string        { return "proxy" }
+func (p *proxyTool) Description() string { return "Proxies to a downstream service" }
+func (p *proxyTool) Parameters() any     { return nil }
+func (p *proxyTool) Execute(ctx context.Context, args any) (any, error) {
+	panic("router must use ExecuteRaw for RawTools")
+}
+func (p *proxyTool) JSONSchema() string {
+	return `{"type":"object","properties":{"anything":{"type":"string"}},"additionalProperties":true}`
+}
+func (p *proxyTool) ExecuteRaw(ctx context.Context, args json.RawMessage) (any, error) {
+	p.gotRaw = args
+	return map[string]string{"status": "forwarded"}, nil
+}
+
+func TestRawToolPath(t *testing.T) {
+	rawArgs := `{"anything": "goes", "extra": {"nested": true}}`
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("proxy", rawArgs),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	tool := &proxyTool{}
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if string(tool.gotRaw) != rawArgs {
+		t.Errorf("raw args = %s, want untouched %s", tool.gotRaw, rawArgs)
+	}
+
+	// The hand-written schema reaches the provider verbatim.
+	def := fake.calls[0].ToolDefs[0]
+	if def.RawSchema == nil || def.RawSchema["additionalProperties"] != true {
+		t.Errorf("def = %+v, want raw schema used verbatim", def)
+	}
+}
@@ -0,0 +1,125 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestSchemaRetriesReask(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`this is not json at all`),
+		textResponse(`{"items": ["fixed"]}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("list")
+	req.SchemaRetries = 1
+
+	out, meta, err := ExecuteWithMeta[itemList](context.Background(), r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Items) != 1 || out.Items[0] != "fixed" {
+		t.Errorf("out = %+v", out)
+	}
+	// Usage aggregated across both attempts (15 tokens each).
+	if meta.Usage.TotalTokens != 30 {
+		t.Errorf("usage = %+v, want both attempts counted", meta.Usage)
+	}
+
+	// The retry carried the invalid output and a corrective prompt.
+	msgs := fake.calls[1].Messages
+	last := msgs[len(msgs)-1]
+	if last.Role != RoleUser || !strings.Contains(last.Content, "Respond ONLY with corrected JSON") {
+		t.Errorf("corrective message = %+v", last)
+	}
+	if msgs[len(msgs)-2].Content != "this is not json at all" {
+		t.Errorf("invalid output not included: %+v", msgs[len(msgs)-2])
+	}
+}
+
+func TestSchemaRetriesExhausted(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`nope`), textResponse(`still nope`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("list")
+	req.SchemaRetries = 1
+	_, err := Execute[itemList](context.Background(), r, req)
+	if !errors.Is(err, ErrStructuredOutput) {
+		t.Errorf("err = %v", err)
+	}
+	if len(fake.calls) != 2 {
+		t.Errorf("made %d calls, want 2", len(fake.calls))
+	}
+}
+
+func TestSchemaRetriesDoNotRerunTools(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`),
+		textResponse(`garbage`),
+		textResponse(`{"items": ["ok"]}`),
+	}}
+	tool := &echoTool{}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	req.SchemaRetries = 1
+
+	if _, err := Execute[itemList](context.Background(), r, req); err != nil {
+		t.Fatal(err)
+	}
+	if tool.executed != 1 {
+		t.Errorf("tool ran %d times; retries must not re-run tools", tool.executed)
+	}
+	if len(fake.calls[2].ToolDefs) != 0 {
+		t.Error("retry request should not advertise tools")
+	}
+}
+
+func TestZeroRetriesKeepsOldBehavior(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`nope`)}}
+	r := newTestRouter(t, testModels(), fake)
+	_, err := Execute[itemList](context.Background(), r, userRequest("list"))
+	if !errors.Is(err, ErrStructuredOutput) || len(fake.calls) != 1 {
+		t.Errorf("err=%v calls=%d", err, len(fake.calls))
+	}
+}
+
+func TestSchemaRetriesTriggeredBySchemaViolation(t *testing.T) {
+	// Parses into the struct (zero values absorb) but violates the schema;
+	// with SchemaRetries set, the router re-asks even without
+	// ValidateOutput.
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"wrong_field": 1}`),
+		textResponse(`{"items": ["ok"]}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("list")
+	req.SchemaRetries = 1
+	out, err := Execute[itemList](context.Background(), r, req)
+	if err != nil || len(out.Items) != 1 {
+		t.Errorf("out=%+v err=%v", out, err)
+	}
+	if len(fake.calls) != 2 {
+		t.Errorf("calls = %d, want a re-ask", len(fake.calls))
+	}
+}
+
+func TestStructuredErrorCarriesViolationDetails(t *testing.T) {
+	// Valid JSON whose types don't match T: the error should say why.
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items": "not-an-array"}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	_, err := Execute[itemList](context.Background(), r, userRequest("list"))
+	if !errors.Is(err, ErrStructuredOutput) {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(err.Error(), "items") || !strings.Contains(err.Error(), "expected array") {
+		t.Errorf("error lacks violation details: %v", err)
+	}
+}
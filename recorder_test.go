@@ -0,0 +1,84 @@
+package llmrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+func TestPayloadRecorderReceivesRedactedPayloads(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+
+	type record struct {
+		provider  string
+		req, resp string
+		status    int
+	}
+	var records []record
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gpt": {Provider: "openai", Model: "gpt-4o", APIKey: "sk-secret-key", BaseURL: srv.URL},
+	}}
+	r, err := NewRouter(cfg, WithPayloadRecorder(func(provider string, req, resp []byte, status int) {
+		records = append(records, record{provider, string(req), string(resp), status})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "gpt", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	rec := records[0]
+	if rec.provider != "openai" || rec.status != 200 {
+		t.Errorf("record = %+v", rec)
+	}
+	if !strings.Contains(rec.req, `"messages"`) || !strings.Contains(rec.resp, `"choices"`) {
+		t.Errorf("payloads not captured: req=%q resp=%q", rec.req, rec.resp)
+	}
+	if strings.Contains(rec.req, "sk-secret-key") || strings.Contains(rec.resp, "sk-secret-key") {
+		t.Error("API key leaked into recorded payloads")
+	}
+}
+
+func TestPerModelCustomHeaders(t *testing.T) {
+	t.Setenv("GATEWAY_KEY", "gw-secret")
+	var gotGateway, gotTeam string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotGateway = r.Header.Get("X-Api-Gateway-Key")
+		gotTeam = r.Header.Get("X-Team")
+		io.WriteString(w, `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	yaml := `
+llm:
+  models:
+    gated:
+      provider: openai
+      model: gpt-4o
+      api_key: k
+      base_url: ` + srv.URL + `
+      headers:
+        X-Api-Gateway-Key: ${GATEWAY_KEY}
+        X-Team: platform
+`
+	r, err := NewFromConfigBytes([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "gated", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if gotGateway != "gw-secret" || gotTeam != "platform" {
+		t.Errorf("headers = %q / %q", gotGateway, gotTeam)
+	}
+}
@@ -0,0 +1,137 @@
+package llmrouter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestReloadSwapsModels(t *testing.T) {
+	r := newTestRouter(t, map[string]config.ModelConfig{
+		"old": {Provider: "test", Model: "m-old"},
+	}, &fakeClient{responses: []*core.RawResponse{textResponse("ok")}})
+
+	if err := r.Reload(&config.LLMConfig{Models: map[string]config.ModelConfig{
+		"new": {Provider: "openai", Model: "gpt-4o", APIKey: "k2"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.ModelFor(Request{Model: "old"}); err == nil {
+		t.Error("removed model still resolvable after reload")
+	}
+	if key, err := r.ModelFor(Request{Model: "new"}); err != nil || key != "new" {
+		t.Errorf("new model: %q, %v", key, err)
+	}
+}
+
+func TestReloadRejectsBadConfig(t *testing.T) {
+	r := newTestRouter(t, map[string]config.ModelConfig{
+		"keep": {Provider: "test", Model: "m"},
+	}, &fakeClient{})
+
+	if err := r.Reload(&config.LLMConfig{}); err == nil {
+		t.Fatal("empty reload should be rejected")
+	}
+	if err := r.Reload(&config.LLMConfig{Models: map[string]config.ModelConfig{
+		"broken": {Provider: "nope", Model: ""},
+	}}); err == nil {
+		t.Fatal("invalid reload should be rejected")
+	}
+	// The previous configuration must survive both failures.
+	if key, err := r.ModelFor(Request{Model: "keep"}); err != nil || key != "keep" {
+		t.Errorf("previous config lost: %q, %v", key, err)
+	}
+}
+
+func TestReloadInvalidatesStaleClients(t *testing.T) {
+	cfgA := config.ModelConfig{Provider: "openai", Model: "gpt-4o", APIKey: "old-key"}
+	r, err := NewRouter(&config.LLMConfig{Models: map[string]config.ModelConfig{"gpt": cfgA}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.getClient(cfgA); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgB := cfgA
+	cfgB.APIKey = "rotated-key"
+	if err := r.Reload(&config.LLMConfig{Models: map[string]config.ModelConfig{"gpt": cfgB}}); err != nil {
+		t.Fatal(err)
+	}
+	r.mu.Lock()
+	_, stale := r.clients[clientCacheKey(cfgA)]
+	r.mu.Unlock()
+	if stale {
+		t.Error("client built with the rotated-out key is still cached")
+	}
+}
+
+func TestReloadConcurrentWithRequests(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+	mc := config.ModelConfig{Provider: "openai", Model: "gpt-4o", APIKey: "k", BaseURL: srv.URL}
+	r, err := NewRouter(&config.LLMConfig{Models: map[string]config.ModelConfig{"m": mc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if _, err := r.ExecuteRaw(context.Background(), Request{Model: "m", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+				t.Errorf("request during reload: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			rotated := mc
+			if i%2 == 0 {
+				rotated.APIKey = "k2"
+			}
+			if err := r.Reload(&config.LLMConfig{Models: map[string]config.ModelConfig{"m": rotated}}); err != nil {
+				t.Errorf("reload: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestReloadAppliesNewRateLimits(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+	tight := config.ModelConfig{Provider: "openai", Model: "gpt-4o", APIKey: "k", BaseURL: srv.URL, RPMLimit: 1}
+	r, err := NewRouter(&config.LLMConfig{Models: map[string]config.ModelConfig{"m": tight}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := Request{Model: "m", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	if _, err := r.ExecuteRaw(ctx, req); err == nil {
+		t.Fatal("second call should have hit the 1 RPM limit")
+	}
+	cancel()
+
+	// Raising the limit via reload must take effect without a restart.
+	loose := tight
+	loose.RPMLimit = 100
+	if err := r.Reload(&config.LLMConfig{Models: map[string]config.ModelConfig{"m": loose}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Errorf("call after reload still limited by the old config: %v", err)
+	}
+}
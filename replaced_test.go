@@ -0,0 +1,54 @@
+package llmrouter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestReplacedByReroutesWithWarning(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"old-pro": {Provider: "test", Model: "m-old", ReplacedBy: "new-pro"},
+		"new-pro": {Provider: "test", Model: "m-new"},
+	}
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-new": {responses: []*core.RawResponse{textResponse("from replacement")}},
+	}}
+	r, buf := routerWithLogBuffer(t, models, mm)
+
+	out, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Model:    "old-pro",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "from replacement" || meta.ModelKey != "new-pro" {
+		t.Errorf("out=%q model=%q", out, meta.ModelKey)
+	}
+	log := buf.String()
+	if !strings.Contains(log, "old-pro") || !strings.Contains(log, "new-pro") {
+		t.Errorf("deprecation log should name both keys:\n%s", log)
+	}
+}
+
+func TestAutoSelectSkipsReplacedModels(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-old": {Provider: "test", Model: "m-old", ReplacedBy: "bbb-new"},
+		"bbb-new": {Provider: "test", Model: "m-new"},
+	}
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-new": {responses: []*core.RawResponse{textResponse("ok")}},
+	}}
+	r := newTestRouter(t, models, mm)
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "bbb-new" {
+		t.Errorf("auto-select chose %q, replaced models should delegate", meta.ModelKey)
+	}
+}
@@ -0,0 +1,79 @@
+package llmrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestRequestIDInLogsAndMetadata(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+	r, buf := routerWithLogBuffer(t, testModels(), fake)
+
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.RequestID = "req_fixed123"
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.RequestID != "req_fixed123" {
+		t.Errorf("meta.RequestID = %q", meta.RequestID)
+	}
+	logs := buf.String()
+	lines := strings.Split(strings.TrimSpace(logs), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "llm call") || strings.Contains(line, "tool executed") {
+			if !strings.Contains(line, "request_id=req_fixed123") {
+				t.Errorf("line missing request_id: %s", line)
+			}
+		}
+	}
+	if !strings.Contains(logs, "turn=0") || !strings.Contains(logs, "turn=1") {
+		t.Errorf("turn numbers missing:\n%s", logs)
+	}
+}
+
+func TestRequestIDGeneratedWhenEmpty(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(meta.RequestID, "req_") || len(meta.RequestID) < 10 {
+		t.Errorf("generated RequestID = %q", meta.RequestID)
+	}
+}
+
+func TestRequestIDForwardedToProvider(t *testing.T) {
+	var gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Request-Id")
+		io.WriteString(w, `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	r, err := NewRouter(&config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gpt": {Provider: "openai", Model: "gpt-4o", APIKey: "k", BaseURL: srv.URL},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := Request{Model: "gpt", RequestID: "req_wire42", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if gotHeader != "req_wire42" {
+		t.Errorf("X-Request-Id = %q", gotHeader)
+	}
+}
@@ -0,0 +1,102 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// catalogResolver serves a fixed catalog, recording lookups.
+type catalogResolver struct {
+	advertised []Tool
+	catalog    map[string]Tool
+	lookups    []string
+}
+
+func (c *catalogResolver) Tools(ctx context.Context) ([]Tool, error) {
+	return c.advertised, nil
+}
+
+func (c *catalogResolver) Lookup(ctx context.Context, name string) (Tool, error) {
+	c.lookups = append(c.lookups, name)
+	t, ok := c.catalog[name]
+	if !ok {
+		return nil, fmt.Errorf("no tool %q", name)
+	}
+	return t, nil
+}
+
+func TestToolResolverAdvertisesAndResolves(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"hi"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	tool := &echoTool{}
+	resolver := &catalogResolver{
+		advertised: []Tool{tool},
+		catalog:    map[string]Tool{"echo": tool},
+	}
+	req := userRequest("go")
+	req.ToolResolver = resolver
+
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if len(fake.calls[0].ToolDefs) != 1 || fake.calls[0].ToolDefs[0].Name != "echo" {
+		t.Errorf("advertised defs = %+v", fake.calls[0].ToolDefs)
+	}
+	if tool.executed != 1 {
+		t.Errorf("tool executed %d times", tool.executed)
+	}
+}
+
+func TestToolResolverLookupBeyondAdvertised(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("hidden", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	hidden := &ctxProbeTool{onExecute: func(context.Context) {}}
+	resolver := &catalogResolver{
+		advertised: []Tool{&echoTool{}},
+		catalog:    map[string]Tool{"hidden": hidden, "echo": &echoTool{}},
+	}
+	// The fake model calls "hidden", which wasn't advertised; Lookup still
+	// resolves it... except our probe tool is named ctxprobe, so register
+	// it under the name the model used.
+	resolver.catalog["hidden"] = renamedTool{Tool: hidden, name: "hidden"}
+
+	req := userRequest("go")
+	req.ToolResolver = resolver
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(resolver.lookups) == 0 || resolver.lookups[0] != "hidden" {
+		t.Errorf("lookups = %v", resolver.lookups)
+	}
+}
+
+type renamedTool struct {
+	Tool
+	name string
+}
+
+func (r renamedTool) Name() string { return r.name }
+
+func TestToolsAndResolverMutuallyExclusive(t *testing.T) {
+	r := newTestRouter(t, testModels(), &fakeClient{})
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.ToolResolver = &catalogResolver{}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,1431 @@
+package llmrouter
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/providers/gemini"
+	"github.com/LizzyG/llmrouter/internal/providers/openai"
+	"github.com/LizzyG/llmrouter/internal/ratelimit"
+	"github.com/LizzyG/llmrouter/internal/retry"
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+const defaultMaxToolTurns = 8
+
+// Router dispatches requests to configured provider models.
+// routerConfig is the immutable configuration snapshot a Router serves
+// from. Reload swaps the whole snapshot atomically; in-flight requests
+// keep reading the one they started with.
+type routerConfig struct {
+	models       map[string]config.ModelConfig
+	aliases      map[string]string
+	defaultModel string
+}
+
+type Router struct {
+	cfg    atomic.Pointer[routerConfig]
+	logger *slog.Logger
+
+	maxToolTurns int
+
+	// now is the clock used for sunset checks; overridable in tests.
+	now func() time.Time
+
+	// rng drives weighted traffic splits; seedable in tests.
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	// tokenizer estimates prompt tokens for providers without a native
+	// counting API.
+	tokenizer Tokenizer
+
+	// recorder, when set, receives redacted wire payloads for every call.
+	recorder core.PayloadRecorder
+
+	// failover enables mid-tool-loop continuation on an alternative model
+	// after transient failures; see WithFailover.
+	failover bool
+
+	// strictStructured turns the explicit-model structured-output warning
+	// into a typed error; see WithStrictStructuredSupport.
+	strictStructured bool
+
+	// preToolHook and postToolHook wrap every tool execution; see
+	// WithToolHook.
+	preToolHook  func(ctx context.Context, info ToolCallInfo) (context.Context, error)
+	postToolHook func(ctx context.Context, info ToolCallInfo, result any, err error)
+
+	// selection orders auto-selection candidates; see WithSelectionStrategy.
+	selection SelectionStrategy
+
+	// shadow mirrors sampled traffic onto an evaluation model; see
+	// WithShadowModel.
+	shadow *shadowConfig
+
+	// sem bounds concurrent provider calls when WithMaxConcurrentCalls is
+	// set; nil means unlimited.
+	sem      chan struct{}
+	inFlight atomic.Int64
+
+	mu      sync.Mutex
+	clients map[string]core.RawClient
+	// clientExpiry tracks when clients built from resolved secrets must be
+	// rebuilt (credential TTL window).
+	clientExpiry map[string]time.Time
+	limiters     map[string]*ratelimit.Limiter
+
+	// secretResolver resolves credential references lazily; see
+	// WithSecretResolver.
+	secretResolver SecretResolver
+
+	// metrics receives instrumentation callbacks; see WithMetrics.
+	metrics Metrics
+
+	// tracer is non-nil when OTel instrumentation is enabled; see
+	// WithTracerProvider.
+	tracer trace.Tracer
+
+	// usage accumulates cumulative per-model counters for Usage().
+	usage *usageAccumulator
+
+	// usageCallback receives one UsageReport per completed request; see
+	// WithUsageCallback.
+	usageCallback func(ctx context.Context, report UsageReport)
+	// deprecationWarned tracks model keys already warned about, so each
+	// deprecated model is logged once per router.
+	deprecationWarned map[string]bool
+}
+
+// NewRouter builds a Router from an already-loaded configuration.
+func NewRouter(cfg *config.LLMConfig, opts ...Option) (*Router, error) {
+	if cfg == nil || len(cfg.Models) == 0 {
+		return nil, fmt.Errorf("llmrouter: configuration contains no models")
+	}
+	r := &Router{
+		logger:            slog.Default(),
+		maxToolTurns:      defaultMaxToolTurns,
+		now:               time.Now,
+		rng:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		tokenizer:         estimatorTokenizer{},
+		clients:           map[string]core.RawClient{},
+		clientExpiry:      map[string]time.Time{},
+		limiters:          map[string]*ratelimit.Limiter{},
+		usage:             newUsageAccumulator(),
+		deprecationWarned: map[string]bool{},
+	}
+	r.cfg.Store(&routerConfig{
+		models:       cfg.Models,
+		aliases:      cfg.Aliases,
+		defaultModel: cfg.DefaultModel,
+	})
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// execLogger returns the per-execution logger (request ID attached) when
+// one is set, falling back to the router's logger.
+func (r *Router) execLogger(req Request) *slog.Logger {
+	if req.logger != nil {
+		return req.logger
+	}
+	return r.logger
+}
+
+// newRequestID produces a short random identifier for log correlation.
+func newRequestID() string {
+	var b [6]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		return fmt.Sprintf("req_%d", time.Now().UnixNano())
+	}
+	return "req_" + hex.EncodeToString(b[:])
+}
+
+// snapshot returns the configuration the current operation should use.
+func (r *Router) snapshot() *routerConfig { return r.cfg.Load() }
+
+// Reload atomically swaps the router's model configuration. Cached provider
+// clients whose model config changed are invalidated (identity is part of
+// the cache key, so stale ones simply stop being referenced and new ones
+// are built lazily); per-model rate limiters for removed keys are dropped.
+// In-flight requests keep using the snapshot they started with. The new
+// configuration must be non-empty; otherwise the previous one is kept and
+// an error returned.
+func (r *Router) Reload(cfg *config.LLMConfig) error {
+	if cfg == nil || len(cfg.Models) == 0 {
+		return fmt.Errorf("llmrouter: reload rejected: configuration contains no models")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("llmrouter: reload rejected: %w", err)
+	}
+	r.cfg.Store(&routerConfig{
+		models:       cfg.Models,
+		aliases:      cfg.Aliases,
+		defaultModel: cfg.DefaultModel,
+	})
+
+	// Drop cached clients and limiters that no current model can reach.
+	live := map[string]bool{}
+	for _, mc := range cfg.Models {
+		live[clientCacheKey(mc)] = true
+	}
+	r.mu.Lock()
+	for key := range r.clients {
+		if !live[key] {
+			delete(r.clients, key)
+		}
+	}
+	liveLimiters := map[string]bool{}
+	for key, mc := range cfg.Models {
+		liveLimiters[limiterKey(key, mc)] = true
+	}
+	for key := range r.limiters {
+		if !liveLimiters[key] {
+			delete(r.limiters, key)
+		}
+	}
+	r.mu.Unlock()
+	r.logger.Info("configuration reloaded", "models", len(cfg.Models))
+	return nil
+}
+
+// ReloadFromFile re-reads a config file and applies it via Reload; load or
+// validation failures leave the previous configuration in place.
+func (r *Router) ReloadFromFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("llmrouter: reload: %w", err)
+	}
+	cfg, err := config.LoadFromBytes(b)
+	if err != nil {
+		return fmt.Errorf("llmrouter: reload: %w", err)
+	}
+	return r.Reload(cfg)
+}
+
+// NewFromFile builds a Router from config.yaml (or LLM_CONFIG_PATH),
+// with per-path caching of the parsed file.
+func NewFromFile(opts ...Option) (*Router, error) {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(cfg, opts...)
+}
+
+// NewFromEnv builds a Router entirely from LLM__MODELS__* environment
+// variables, for deployments that ship no config file.
+func NewFromEnv(opts ...Option) (*Router, error) {
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(cfg, opts...)
+}
+
+// NewFromFileAt builds a Router from an explicit config file path, loading
+// it fresh — two routers in one process can use different files.
+func NewFromFileAt(path string, opts ...Option) (*Router, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(cfg, opts...)
+}
+
+// NewFromConfigBytes builds a Router from raw YAML configuration — for
+// go:embed'ed config or bytes fetched at boot — bypassing the file path and
+// its process-wide cache entirely while still applying env-var
+// interpolation and LLM__ overrides.
+func NewFromConfigBytes(b []byte, opts ...Option) (*Router, error) {
+	cfg, err := config.LoadFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(cfg, opts...)
+}
+
+// execResult is what one full execution (including the tool loop) produces.
+type execResult struct {
+	content string
+	meta    ResponseMeta
+	// transcript is the full conversation as executed: the request messages
+	// plus every assistant tool-call message, tool result, and the final
+	// assistant answer.
+	transcript []core.Message
+	// candidates holds every alternative completion of the final turn when
+	// the request asked for more than one.
+	candidates []core.Candidate
+}
+
+// executeInternal resolves the model and runs the request, falling back
+// through the configured fallback chain when a model fails transiently
+// (timeouts, 5xx, 429s that survived the retry loop). Non-transient errors
+// — bad requests, unknown tools, validation failures — never trigger
+// fallback. The metadata reports which model actually answered.
+func (r *Router) executeInternal(ctx context.Context, req Request, outputSchema string) (res *execResult, err error) {
+	ctx, execSpan := r.startSpan(ctx, "llmrouter.execute")
+	defer func() { endSpan(execSpan, err) }()
+
+	started := time.Now()
+	// The report context must survive the request's own deadline: defers
+	// run LIFO, so the timeout cancel below fires before reportUsage, and
+	// the callback runs asynchronously besides.
+	reportCtx := context.WithoutCancel(ctx)
+	defer func() { r.reportUsage(reportCtx, req, res, err, started) }()
+
+	if req.RequestID == "" {
+		req.RequestID = newRequestID()
+	}
+	req.logger = r.logger.With("request_id", req.RequestID)
+
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	if req.ToolResolver != nil {
+		if len(req.Tools) > 0 {
+			return nil, fmt.Errorf("llmrouter: Tools and ToolResolver are mutually exclusive")
+		}
+		tools, err := req.ToolResolver.Tools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("llmrouter: resolving tools: %w", err)
+		}
+		req.Tools = tools
+	}
+	if err := validateTools(req.Tools); err != nil {
+		return nil, err
+	}
+
+	modelKey, cfg, err := r.selectModel(req)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := req.FallbackModels
+	if chain == nil {
+		chain = cfg.Fallbacks
+	}
+	if r.failover && len(chain) == 0 {
+		chain = r.alternativeKeys(modelKey, req)
+	}
+
+	r.maybeShadow(req, outputSchema, modelKey)
+
+	accumulated := make([]core.Message, len(req.Messages))
+	copy(accumulated, req.Messages)
+	res, err = r.executeOnModel(ctx, req, outputSchema, modelKey, cfg, &accumulated)
+
+	// Rotated credentials: on an auth failure for a model whose keys come
+	// from a secret resolver, re-resolve once and retry.
+	if err != nil && errors.Is(err, core.ErrAuthFailed) && r.secretResolver != nil && hasSecretRefs(cfg) {
+		r.execLogger(req).Warn("auth failure with resolved secrets, re-resolving and retrying", "model", modelKey)
+		r.invalidateClient(cfg)
+		accumulated = make([]core.Message, len(req.Messages))
+		copy(accumulated, req.Messages)
+		res, err = r.executeOnModel(ctx, req, outputSchema, modelKey, cfg, &accumulated)
+	}
+
+	// Survive the gap between a provider retiring a model ID and the config
+	// catching up: consult replaced_by once when the provider says the
+	// model doesn't exist.
+	if err != nil && errors.Is(err, core.ErrModelNotFound) {
+		// Re-read the config: a reload may have set replaced_by after this
+		// request resolved its model.
+		cur := r.snapshot().models[modelKey]
+		if repl, ok := r.snapshot().models[cur.ReplacedBy]; ok && cur.ReplacedBy != "" {
+			r.execLogger(req).Warn("provider no longer knows model, rerouting to replacement",
+				"model", modelKey, "replaced_by", cur.ReplacedBy, "error", err)
+			modelKey, cfg = cur.ReplacedBy, repl
+			accumulated = make([]core.Message, len(req.Messages))
+			copy(accumulated, req.Messages)
+			res, err = r.executeOnModel(ctx, req, outputSchema, modelKey, cfg, &accumulated)
+		}
+	}
+
+	for _, fbKey := range chain {
+		if err == nil || !retry.IsTransient(err) || ctx.Err() != nil {
+			break
+		}
+		fbReq := req
+		fbReq.Model = fbKey
+		fbModelKey, fbCfg, selErr := r.selectModel(fbReq)
+		if selErr != nil {
+			r.execLogger(req).Warn("skipping unusable fallback model", "model", fbKey, "error", selErr)
+			continue
+		}
+		r.execLogger(req).Warn("falling back after transient failure",
+			"failed_model", modelKey, "fallback_model", fbModelKey, "error", err, "failover", r.failover)
+		modelKey = fbModelKey
+		if r.failover {
+			// Continue the tool loop where it stopped: replay the
+			// accumulated conversation, with call IDs neutralized so one
+			// provider's identifiers don't confuse another.
+			accumulated = neutralizeCallIDs(accumulated)
+		} else {
+			accumulated = make([]core.Message, len(req.Messages))
+			copy(accumulated, req.Messages)
+		}
+		res, err = r.executeOnModel(ctx, fbReq, outputSchema, fbModelKey, fbCfg, &accumulated)
+	}
+	return res, err
+}
+
+// alternativeKeys lists every other configured model, in deterministic
+// order, that could serve the request — used by WithFailover when no
+// explicit fallback chain is configured.
+func (r *Router) alternativeKeys(exclude string, req Request) []string {
+	keys := make([]string, 0, len(r.snapshot().models))
+	for k := range r.snapshot().models {
+		if k == exclude {
+			continue
+		}
+		probe := req
+		probe.Model = k
+		if _, _, err := r.selectModel(probe); err == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// neutralizeCallIDs rewrites provider-assigned tool call IDs to synthetic
+// neutral ones, preserving call/result pairing, so an accumulated
+// conversation can be replayed against a different provider.
+func neutralizeCallIDs(msgs []core.Message) []core.Message {
+	out := make([]core.Message, len(msgs))
+	copy(out, msgs)
+	ids := map[string]string{}
+	n := 0
+	for i, m := range out {
+		if len(m.ToolCalls) > 0 {
+			calls := make([]core.ToolCall, len(m.ToolCalls))
+			copy(calls, m.ToolCalls)
+			for j := range calls {
+				n++
+				neutral := fmt.Sprintf("call_%d", n)
+				if calls[j].CallID != "" {
+					ids[calls[j].CallID] = neutral
+				}
+				calls[j].CallID = neutral
+			}
+			out[i].ToolCalls = calls
+		}
+		if len(m.ToolResults) > 0 {
+			results := make([]core.ToolResult, len(m.ToolResults))
+			copy(results, m.ToolResults)
+			for j := range results {
+				if neutral, ok := ids[results[j].CallID]; ok {
+					results[j].CallID = neutral
+				}
+			}
+			out[i].ToolResults = results
+		}
+	}
+	return out
+}
+
+// executeOnModel runs the tool loop against one resolved model, reading
+// and extending the conversation through accumulated so a failover can
+// resume mid-loop.
+func (r *Router) executeOnModel(ctx context.Context, req Request, outputSchema, modelKey string, cfg config.ModelConfig, accumulated *[]core.Message) (*execResult, error) {
+	client, err := r.getClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if orZero(req.TopK) > 0 && orZero(req.TopP) != 0 {
+		r.execLogger(req).Warn("both TopK and TopP are set; some providers reject the combination",
+			"model", modelKey, "top_k", *req.TopK, "top_p", *req.TopP)
+	}
+
+	// Structured output is only requested from models that support it; for
+	// the rest we rely on prompt adherence plus JSON repair.
+	if outputSchema != "" && !cfg.SupportsStructuredOutput {
+		outputSchema = ""
+	}
+
+	if len(req.Tools) > 0 && (len(cfg.AllowedTools) > 0 || len(cfg.DeniedTools) > 0) {
+		filtered := make([]Tool, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			name := t.Name()
+			switch {
+			case containsString(cfg.DeniedTools, name):
+				r.execLogger(req).Debug("tool denied for model", "model", modelKey, "tool", name)
+			case len(cfg.AllowedTools) > 0 && !containsString(cfg.AllowedTools, name):
+				r.execLogger(req).Debug("tool not in model's allow list", "model", modelKey, "tool", name)
+			default:
+				filtered = append(filtered, t)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, fmt.Errorf("%w: model %q", ErrAllToolsDenied, modelKey)
+		}
+		req.Tools = filtered
+	}
+
+	defs, skipped, err := r.buildToolDefs(req.Tools, req.ToolDefPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		res0 := make([]Tool, 0, len(req.Tools)-len(skipped))
+		for _, t := range req.Tools {
+			if !containsString(skipped, t.Name()) {
+				res0 = append(res0, t)
+			}
+		}
+		req.Tools = res0
+	}
+
+	applyGenerationDefaults(&req, cfg.Defaults)
+
+	msgs := *accumulated
+
+	if req.FailIfOverContext {
+		prompt := r.estimateTokens(core.CallParams{System: req.System, Messages: msgs, ToolDefs: defs})
+		if err := r.checkContextBudget(req, cfg.ContextWindow, prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &execResult{meta: ResponseMeta{ModelKey: modelKey, Provider: cfg.Provider, SkippedTools: skipped, RequestID: req.RequestID}}
+	consecutiveToolErrs := 0
+	for turn := 0; turn < r.maxToolTurns; turn++ {
+		params := core.CallParams{
+			Model:            cfg.Model,
+			RequestID:        req.RequestID,
+			System:           req.System,
+			Messages:         msgs,
+			Temperature:      req.Temperature,
+			TopP:             req.TopP,
+			TopK:             req.TopK,
+			MaxTokens:        req.MaxTokens,
+			Seed:             req.Seed,
+			CandidateCount:   req.CandidateCount,
+			OutputSchema:     outputSchema,
+			ForceJSON:        req.ForceJSON,
+			StrictJSONSchema: cfg.SupportsJSONSchema,
+			StrictTools:      req.StrictTools && cfg.SupportsJSONSchema,
+			ToolDefs:         defs,
+			ToolChoice:       req.ToolChoice,
+			Extra:            req.ProviderOptions[cfg.Provider],
+		}
+		req.emit(ToolLoopEvent{Type: EventModelTurnStarted, Turn: turn})
+		estTokens := 0
+		limiter := r.limiterFor(modelKey, cfg)
+		if limiter != nil {
+			estTokens = r.estimateTokens(params) + orZero(req.MaxTokens)
+			if err := limiter.Acquire(ctx, estTokens); err != nil {
+				return nil, fmt.Errorf("model %s: rate limit wait: %w", modelKey, err)
+			}
+		}
+		if err := r.acquireCallSlot(ctx); err != nil {
+			return nil, fmt.Errorf("model %s: %w", modelKey, err)
+		}
+		callCtx := ctx
+		if cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Timeout))
+			defer cancel()
+		}
+		callCtx, callSpan := r.startSpan(callCtx, "llm.call",
+			attribute.String("gen_ai.system", cfg.Provider),
+			attribute.String("gen_ai.request.model", cfg.Model),
+			attribute.Int("llmrouter.turn", turn),
+		)
+		start := time.Now()
+		resp, err := client.Call(callCtx, params)
+		endCallSpan(callSpan, resp, err)
+		r.releaseCallSlot()
+		if r.metrics != nil {
+			var usage core.Usage
+			if resp != nil {
+				usage = resp.Usage
+			}
+			r.metrics.ObserveCall(cfg.Provider, modelKey, usage, time.Since(start), err)
+		}
+		{
+			var u core.Usage
+			if resp != nil {
+				u = resp.Usage
+			}
+			r.usage.record(modelKey, u.PromptTokens, u.CompletionTokens, callCost(cfg, u), err != nil)
+		}
+		if limiter != nil && resp != nil {
+			limiter.Reconcile(estTokens, resp.Usage.TotalTokens)
+		}
+		if err != nil {
+			*accumulated = msgs
+			return nil, fmt.Errorf("model %s: %w", modelKey, err)
+		}
+		res.meta.Usage.Add(resp.Usage)
+		res.meta.TurnUsage = append(res.meta.TurnUsage, resp.Usage)
+		res.meta.TurnLatencies = append(res.meta.TurnLatencies, time.Since(start))
+		res.meta.TurnTimings = append(res.meta.TurnTimings, resp.Timing)
+		cost := callCost(cfg, resp.Usage)
+		res.meta.Cost += cost
+		r.execLogger(req).Info("llm call",
+			"provider", cfg.Provider,
+			"model", modelKey,
+			"turn", turn,
+			"duration", time.Since(start),
+			"ttfb", resp.Timing.TTFB,
+			"prompt_tokens", resp.Usage.PromptTokens,
+			"completion_tokens", resp.Usage.CompletionTokens,
+			"cost", cost,
+		)
+
+		res.meta.FinishReason = resp.FinishReason
+		res.meta.Truncated = resp.FinishReason == core.FinishLength
+
+		if len(resp.ToolCalls) == 0 {
+			res.content = resp.Content
+			res.candidates = resp.Candidates
+			res.transcript = append(msgs, core.Message{Role: core.RoleAssistant, Content: resp.Content})
+			if req.CaptureTranscript {
+				res.meta.Transcript = res.transcript
+			}
+			req.emit(ToolLoopEvent{Type: EventFinalAnswer, Turn: turn, Content: resp.Content})
+			return res, nil
+		}
+
+		// Gemini returns no call IDs; synthesize deterministic ones so two
+		// calls to the same function stay positionally paired with their
+		// results.
+		for i := range resp.ToolCalls {
+			if resp.ToolCalls[i].CallID == "" {
+				resp.ToolCalls[i].CallID = fmt.Sprintf("call_%d_%d", turn, i)
+			}
+		}
+
+		res.meta.ToolTurns++
+		msgs = append(msgs, core.Message{
+			Role:      core.RoleAssistant,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
+		})
+		results, toolErr, err := r.executeToolCalls(ctx, req, turn, defs, resp.ToolCalls)
+		if err != nil {
+			*accumulated = msgs
+			return nil, err
+		}
+		if toolErr != nil {
+			consecutiveToolErrs++
+			if consecutiveToolErrs >= maxConsecutiveToolErrorTurns {
+				*accumulated = msgs
+				return nil, fmt.Errorf("llmrouter: %d consecutive turns with tool errors: %w", consecutiveToolErrs, toolErr)
+			}
+		} else {
+			consecutiveToolErrs = 0
+		}
+		msgs = append(msgs, core.Message{Role: core.RoleTool, ToolResults: results})
+	}
+	*accumulated = msgs
+	return nil, ErrToolLoopLimit
+}
+
+// executeToolCalls runs each requested tool and collects results to feed back
+// to the model. Arguments are validated against the tool's declared
+// parameters first; violations are reported back to the model in the
+// deterministic format from util.FormatViolations so it can correct itself.
+// Under ToolErrorReport, execution failures are also fed back (returned as
+// toolErr) rather than aborting.
+func (r *Router) executeToolCalls(ctx context.Context, req Request, turn int, defs []core.ToolDef, calls []core.ToolCall) (results []core.ToolResult, toolErr error, fatal error) {
+	for _, tc := range calls {
+		req.emit(ToolLoopEvent{Type: EventToolCallRequested, Turn: turn, ToolName: tc.Name, Args: tc.Args})
+		tool := findTool(req.Tools, tc.Name)
+		if tool == nil && req.ToolResolver != nil {
+			resolved, lookupErr := req.ToolResolver.Lookup(ctx, tc.Name)
+			if lookupErr == nil {
+				tool = resolved
+			}
+		}
+		if tool == nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownTool, tc.Name)
+		}
+		def := findToolDef(defs, tc.Name)
+		if vs := util.ValidateToolArgs(tc.Args, def, req.StrictToolArgs); len(vs) > 0 {
+			formatted := "invalid arguments:\n" + util.FormatViolations(vs)
+			// Misspelled fields are recoverable (and usually drag a
+			// missing-required violation along with them): always send the
+			// model a corrective message listing the valid names rather
+			// than aborting.
+			if req.ToolErrorMode != ToolErrorReport && !hasUnknownField(vs) {
+				return nil, nil, fmt.Errorf("%w: tool %s: %s", ErrInvalidToolArgs, tc.Name, formatted)
+			}
+			r.execLogger(req).Warn("tool arguments failed validation, reporting to model", "tool", tc.Name, "violations", len(vs))
+			toolErr = fmt.Errorf("%w: tool %s", ErrInvalidToolArgs, tc.Name)
+			results = append(results, core.ToolResult{
+				CallID:  tc.CallID,
+				Name:    tc.Name,
+				Content: map[string]any{"error": formatted},
+			})
+			continue
+		}
+		var args any
+		rawTool, isRaw := tool.(RawTool)
+		if !isRaw {
+			var derr error
+			args, derr = decodeArgs(tool, tc.Args)
+			if derr != nil {
+				return nil, nil, fmt.Errorf("tool %s: decoding arguments: %w", tc.Name, derr)
+			}
+		}
+		toolCtx := ctx
+		if req.ToolTimeout > 0 {
+			var cancel context.CancelFunc
+			toolCtx, cancel = context.WithTimeout(ctx, req.ToolTimeout)
+			defer cancel()
+		}
+		toolCtx, toolSpan := r.startSpan(toolCtx, "llm.tool",
+			attribute.String("llmrouter.tool.name", tc.Name),
+			attribute.String("llmrouter.tool.call_id", tc.CallID),
+		)
+		info := ToolCallInfo{CallID: tc.CallID, Name: tc.Name, Args: tc.Args, Turn: turn}
+		if req.ApprovalFunc != nil && toolRequiresApproval(tool) {
+			decision, aerr := req.ApprovalFunc(toolCtx, info)
+			if aerr != nil {
+				aerr = fmt.Errorf("tool %s: approval: %w", tc.Name, aerr)
+				endSpan(toolSpan, aerr)
+				return nil, nil, aerr
+			}
+			switch decision {
+			case Approve:
+			case Deny:
+				r.execLogger(req).Warn("tool call denied by approval callback", "tool", tc.Name)
+				endSpan(toolSpan, nil)
+				results = append(results, core.ToolResult{
+					CallID:  tc.CallID,
+					Name:    tc.Name,
+					Content: map[string]any{"error": "tool call was denied by a human reviewer; do not retry this call"},
+				})
+				continue
+			case Abort:
+				err := fmt.Errorf("%w: %s", ErrToolCallAborted, tc.Name)
+				endSpan(toolSpan, err)
+				return nil, nil, err
+			default:
+				err := fmt.Errorf("tool %s: approval returned unknown decision %d", tc.Name, decision)
+				endSpan(toolSpan, err)
+				return nil, nil, err
+			}
+		}
+		if r.preToolHook != nil {
+			hookCtx, hookErr := r.preToolHook(toolCtx, info)
+			if hookErr != nil {
+				if req.ToolErrorMode != ToolErrorReport {
+					hookErr = fmt.Errorf("tool %s: pre-hook: %w", tc.Name, hookErr)
+					endSpan(toolSpan, hookErr)
+					return nil, nil, hookErr
+				}
+				r.execLogger(req).Warn("tool pre-hook rejected call, reporting to model", "tool", tc.Name, "error", hookErr)
+				toolErr = fmt.Errorf("tool %s: pre-hook: %w", tc.Name, hookErr)
+				result := map[string]any{"error": hookErr.Error()}
+				if r.postToolHook != nil {
+					r.postToolHook(toolCtx, info, result, hookErr)
+				}
+				endSpan(toolSpan, hookErr)
+				results = append(results, core.ToolResult{CallID: tc.CallID, Name: tc.Name, Content: result})
+				continue
+			}
+			if hookCtx != nil {
+				toolCtx = hookCtx
+			}
+		}
+		start := time.Now()
+		var out any
+		var err error
+		if isRaw {
+			out, err = rawTool.ExecuteRaw(toolCtx, tc.Args)
+		} else {
+			out, err = tool.Execute(toolCtx, args)
+		}
+		if r.metrics != nil {
+			r.metrics.ObserveToolExecution(tc.Name, time.Since(start), err)
+		}
+		endSpan(toolSpan, err)
+		if err != nil {
+			if req.ToolErrorMode != ToolErrorReport {
+				if r.postToolHook != nil {
+					r.postToolHook(toolCtx, info, nil, err)
+				}
+				return nil, nil, fmt.Errorf("tool %s: %w", tc.Name, err)
+			}
+			r.execLogger(req).Warn("tool failed, reporting to model", "tool", tc.Name, "error", err)
+			toolErr = fmt.Errorf("tool %s: %w", tc.Name, err)
+			result := map[string]any{"error": err.Error()}
+			if r.postToolHook != nil {
+				r.postToolHook(toolCtx, info, result, err)
+			}
+			results = append(results, core.ToolResult{
+				CallID:  tc.CallID,
+				Name:    tc.Name,
+				Content: result,
+			})
+			continue
+		}
+		r.execLogger(req).Debug("tool executed", "tool", tc.Name, "duration", time.Since(start))
+		if r.postToolHook != nil {
+			r.postToolHook(toolCtx, info, out, nil)
+		}
+		if req.OnEvent != nil {
+			serialized, _ := json.Marshal(out)
+			req.emit(ToolLoopEvent{
+				Type:       EventToolExecuted,
+				Turn:       turn,
+				ToolName:   tc.Name,
+				Args:       tc.Args,
+				Duration:   time.Since(start),
+				ResultSize: len(serialized),
+			})
+		}
+		results = append(results, core.ToolResult{
+			CallID:  tc.CallID,
+			Name:    tc.Name,
+			Content: out,
+		})
+	}
+	return results, toolErr, nil
+}
+
+// selectModel resolves Request.Model (or auto-selects) and applies
+// web-variant substitution.
+func (r *Router) selectModel(req Request) (string, config.ModelConfig, error) {
+	needsTools := len(req.Tools) > 0 || req.ToolResolver != nil
+	needsFiles := requestHasFiles(req)
+	needsAudio := requestHasAudio(req)
+
+	// Prompt size is only estimated once, and only when some candidate
+	// actually declares a context window.
+	promptEst := -1
+	estimate := func() int {
+		if promptEst < 0 {
+			promptEst = r.promptEstimate(req)
+		}
+		return promptEst
+	}
+
+	if req.Model == "" && r.snapshot().defaultModel != "" {
+		// The default model is validated against the request's requirements
+		// like an explicit choice; auto-selection only applies when no
+		// default is configured.
+		req.Model = r.snapshot().defaultModel
+	}
+
+	if req.Model != "" {
+		key, err := r.resolveAlias(req.Model)
+		if err != nil {
+			return "", config.ModelConfig{}, err
+		}
+		cfg, ok := r.snapshot().models[key]
+		if !ok {
+			if key == req.Model {
+				return "", config.ModelConfig{}, fmt.Errorf("%w: %q", ErrUnknownModel, req.Model)
+			}
+			return "", config.ModelConfig{}, fmt.Errorf("%w: alias %q points to unknown model %q", ErrUnknownModel, req.Model, key)
+		}
+		if len(cfg.Split) > 0 {
+			return r.pickSplitMember(req, key, cfg.Split)
+		}
+		if req.EnableWebSearch && !cfg.SupportsWebSearch && cfg.WebVariant != "" {
+			variant, ok := r.snapshot().models[cfg.WebVariant]
+			if !ok {
+				return "", config.ModelConfig{}, fmt.Errorf("%w: web variant %q of %q", ErrUnknownModel, cfg.WebVariant, key)
+			}
+			key, cfg = cfg.WebVariant, variant
+		}
+		// Transparent rerouting for retired model IDs.
+		for hops := 0; cfg.ReplacedBy != "" && hops < 5; hops++ {
+			repl, ok := r.snapshot().models[cfg.ReplacedBy]
+			if !ok {
+				r.logger.Warn("replaced_by points to unknown model", "model", key, "replaced_by", cfg.ReplacedBy)
+				break
+			}
+			r.warnDeprecatedOnce(key, "model replaced, rerouting", "replaced_by", cfg.ReplacedBy)
+			key, cfg = cfg.ReplacedBy, repl
+		}
+		if needsTools && !cfg.SupportsTools {
+			return "", config.ModelConfig{}, fmt.Errorf("%w: model %q does not support tools", ErrNoCapableModel, key)
+		}
+		if needsFiles && !cfg.SupportsFiles {
+			return "", config.ModelConfig{}, fmt.Errorf("%w: model %q does not support file attachments", ErrNoCapableModel, key)
+		}
+		if needsAudio && !cfg.SupportsAudio {
+			return "", config.ModelConfig{}, fmt.Errorf("%w: model %q does not support audio input", ErrNoCapableModel, key)
+		}
+		for _, tag := range req.RequireTags {
+			if !cfg.HasTag(tag) {
+				return "", config.ModelConfig{}, fmt.Errorf("%w: model %q lacks required tag %q", ErrNoCapableModel, key, tag)
+			}
+		}
+		if cfg.ContextWindow > 0 && estimate()+orZero(req.MaxTokens) > cfg.ContextWindow {
+			return "", config.ModelConfig{}, fmt.Errorf(
+				"%w: model %q: estimated %d prompt tokens + %d max output > context window %d",
+				ErrContextWindowExceeded, key, estimate(), orZero(req.MaxTokens), cfg.ContextWindow)
+		}
+		if req.requireStructured && !cfg.SupportsStructuredOutput {
+			if r.strictStructured {
+				return "", config.ModelConfig{}, fmt.Errorf("%w: model %q does not support structured output", ErrNoCapableModel, key)
+			}
+			r.warnDeprecatedOnce("structured:"+key,
+				"model does not support structured output; relying on prompt adherence and JSON repair", "model", key)
+		}
+		if err := r.checkDeprecation(key, cfg); err != nil {
+			return "", config.ModelConfig{}, err
+		}
+		return key, cfg, nil
+	}
+
+	keys := r.selectionOrder()
+	for _, k := range keys {
+		cfg := r.snapshot().models[k]
+		if needsTools && !cfg.SupportsTools {
+			continue
+		}
+		if req.EnableWebSearch && !cfg.SupportsWebSearch {
+			continue
+		}
+		if needsFiles && !cfg.SupportsFiles {
+			continue
+		}
+		if needsAudio && !cfg.SupportsAudio {
+			continue
+		}
+		if !hasAllTags(cfg, req.RequireTags) {
+			continue
+		}
+		if cfg.ContextWindow > 0 && estimate()+orZero(req.MaxTokens) > cfg.ContextWindow {
+			continue
+		}
+		if req.requireStructured && !cfg.SupportsStructuredOutput {
+			continue
+		}
+		if err := r.checkDeprecation(k, cfg); err != nil {
+			continue
+		}
+		if cfg.ReplacedBy != "" {
+			continue
+		}
+		return k, cfg, nil
+	}
+	return "", config.ModelConfig{}, ErrNoCapableModel
+}
+
+// pickSplitMember resolves a virtual split entry by weighted random choice
+// over the members that could actually serve the request (capability
+// filters applied, weights renormalized over the eligible subset). The
+// chosen key is logged so canary traffic can be traced.
+func (r *Router) pickSplitMember(req Request, virtualKey string, split map[string]int) (string, config.ModelConfig, error) {
+	type member struct {
+		key    string
+		weight int
+	}
+	var eligible []member
+	total := 0
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, m := range keys {
+		weight := split[m]
+		if weight <= 0 {
+			continue
+		}
+		// Nested splits are not supported; members must be concrete.
+		if mc, ok := r.snapshot().models[m]; !ok || len(mc.Split) > 0 {
+			r.logger.Warn("split member unusable", "virtual", virtualKey, "member", m)
+			continue
+		}
+		probe := req
+		probe.Model = m
+		if _, _, err := r.selectModel(probe); err != nil {
+			continue
+		}
+		eligible = append(eligible, member{m, weight})
+		total += weight
+	}
+	if total == 0 {
+		return "", config.ModelConfig{}, fmt.Errorf("%w: no member of split %q can serve the request", ErrNoCapableModel, virtualKey)
+	}
+	r.rngMu.Lock()
+	pick := r.rng.Intn(total)
+	r.rngMu.Unlock()
+	for _, m := range eligible {
+		if pick < m.weight {
+			r.logger.Info("traffic split", "virtual", virtualKey, "chosen", m.key)
+			probe := req
+			probe.Model = m.key
+			return r.selectModel(probe)
+		}
+		pick -= m.weight
+	}
+	// Unreachable; the loop always consumes pick < total.
+	return "", config.ModelConfig{}, ErrNoCapableModel
+}
+
+// resolveAlias follows the alias chain from name to a model key, detecting
+// cycles. A name that is not an alias resolves to itself.
+func (r *Router) resolveAlias(name string) (string, error) {
+	key := name
+	seen := map[string]bool{}
+	for {
+		target, ok := r.snapshot().aliases[key]
+		if !ok {
+			return key, nil
+		}
+		if seen[key] {
+			return "", fmt.Errorf("%w: alias cycle through %q", ErrUnknownModel, name)
+		}
+		seen[key] = true
+		key = target
+	}
+}
+
+// promptEstimate sizes the request's prompt — mapped messages plus tool
+// definitions — with the router's tokenizer.
+func (r *Router) promptEstimate(req Request) int {
+	defs, _, err := r.buildToolDefs(req.Tools, SkipBrokenTools)
+	if err != nil {
+		defs = nil
+	}
+	return r.estimateTokens(core.CallParams{System: req.System, Messages: req.Messages, ToolDefs: defs})
+}
+
+// selectionOrder lists model keys in the order auto-selection considers
+// them: alphabetical by default, or by configured cost per token (input
+// plus output rate) under CheapestFirst, with alphabetical tie-breaking.
+func (r *Router) selectionOrder() []string {
+	keys := make([]string, 0, len(r.snapshot().models))
+	for k := range r.snapshot().models {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if r.selection == CheapestFirst {
+		cost := func(k string) float64 {
+			mc := r.snapshot().models[k]
+			return mc.InputCostPerMTok + mc.OutputCostPerMTok
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return cost(keys[i]) < cost(keys[j])
+		})
+	}
+	return keys
+}
+
+// checkDeprecation warns (once per model per router) when a deprecated model
+// is selected and blocks selection past the model's sunset date unless the
+// config explicitly allows it.
+func (r *Router) checkDeprecation(key string, cfg config.ModelConfig) error {
+	sunset, hasSunset := cfg.Sunset()
+	if hasSunset && !r.now().Before(sunset) {
+		if !cfg.AllowSunset {
+			return fmt.Errorf("%w: model %q was sunset on %s", ErrModelSunset, key, cfg.SunsetDate)
+		}
+		r.warnDeprecatedOnce(key, "model past sunset date", "sunset_date", cfg.SunsetDate)
+		return nil
+	}
+	if cfg.Deprecated {
+		r.warnDeprecatedOnce(key, "model is deprecated")
+	}
+	return nil
+}
+
+func (r *Router) warnDeprecatedOnce(key, msg string, args ...any) {
+	r.mu.Lock()
+	warned := r.deprecationWarned[key]
+	r.deprecationWarned[key] = true
+	r.mu.Unlock()
+	if !warned {
+		r.logger.Warn(msg, append([]any{"model", key}, args...)...)
+	}
+}
+
+// getClient returns the provider client for cfg, constructing and caching
+// it on first use. The cache key includes the credentials and endpoint, not
+// just the provider name, so two models on the same provider with different
+// keys or base URLs get their own clients.
+func (r *Router) getClient(cfg config.ModelConfig) (core.RawClient, error) {
+	cacheKey := clientCacheKey(cfg)
+	r.mu.Lock()
+	if c, ok := r.clients[cacheKey]; ok {
+		expiry, expires := r.clientExpiry[cacheKey]
+		if !expires || r.now().Before(expiry) {
+			r.mu.Unlock()
+			return c, nil
+		}
+		delete(r.clients, cacheKey)
+		delete(r.clientExpiry, cacheKey)
+	}
+	r.mu.Unlock()
+
+	// Secret resolution can hit the network; it must not run under r.mu or
+	// one slow lookup stalls every request on the router.
+	usesSecrets := r.secretResolver != nil && hasSecretRefs(cfg)
+	if usesSecrets {
+		resolved, err := r.resolveSecrets(cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg = resolved
+	}
+	var c core.RawClient
+	retryCfg := retryConfigFor(cfg)
+	if r.metrics != nil {
+		provider := cfg.Provider
+		retryCfg.OnRetry = func(attempt int) { r.metrics.ObserveRetry(provider, attempt) }
+	}
+	switch cfg.Provider {
+	case "openai":
+		oc := openai.New(cfg.Keys(), cfg.BaseURL, r.logger)
+		oc.SetPayloadRecorder(r.recorder)
+		oc.SetHeaders(cfg.Headers)
+		oc.SetRetryConfig(retryCfg)
+		c = oc
+	case "gemini":
+		gc := gemini.New(cfg.Keys(), cfg.BaseURL, r.logger)
+		gc.SetPayloadRecorder(r.recorder)
+		gc.SetHeaders(cfg.Headers)
+		gc.SetRetryConfig(retryCfg)
+		c = gc
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, cfg.Provider)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Another goroutine may have built the client while we resolved; keep
+	// the first still-fresh one.
+	if existing, ok := r.clients[cacheKey]; ok {
+		expiry, expires := r.clientExpiry[cacheKey]
+		if !expires || r.now().Before(expiry) {
+			return existing, nil
+		}
+	}
+	r.clients[cacheKey] = c
+	if usesSecrets {
+		r.clientExpiry[cacheKey] = r.now().Add(secretTTL)
+	} else {
+		delete(r.clientExpiry, cacheKey)
+	}
+	return c, nil
+}
+
+// invalidateClient drops a cached client so the next call rebuilds it with
+// freshly resolved credentials.
+func (r *Router) invalidateClient(cfg config.ModelConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := clientCacheKey(cfg)
+	delete(r.clients, key)
+	delete(r.clientExpiry, key)
+}
+
+// acquireCallSlot blocks until a provider-call slot is free (or ctx ends).
+// Every call in every tool-loop turn is accounted.
+func (r *Router) acquireCallSlot(ctx context.Context) error {
+	if r.sem != nil {
+		select {
+		case r.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	r.inFlight.Add(1)
+	return nil
+}
+
+func (r *Router) releaseCallSlot() {
+	r.inFlight.Add(-1)
+	if r.sem != nil {
+		<-r.sem
+	}
+}
+
+// limiterFor returns the model's shared rate limiter, creating it on first
+// use; nil when the model has no limits configured. The map is keyed by
+// model and limits, so a reload that changes rpm_limit/tpm_limit gets a
+// fresh limiter instead of silently keeping the old rates.
+func (r *Router) limiterFor(modelKey string, cfg config.ModelConfig) *ratelimit.Limiter {
+	if cfg.RPMLimit == 0 && cfg.TPMLimit == 0 {
+		return nil
+	}
+	key := limiterKey(modelKey, cfg)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.limiters[key]
+	if !ok {
+		l = ratelimit.NewLimiter(cfg.RPMLimit, cfg.TPMLimit)
+		r.limiters[key] = l
+	}
+	return l
+}
+
+// limiterKey identifies the limiter a model's current limits need.
+func limiterKey(modelKey string, cfg config.ModelConfig) string {
+	return fmt.Sprintf("%s|rpm=%d|tpm=%d", modelKey, cfg.RPMLimit, cfg.TPMLimit)
+}
+
+// retryConfigFor translates a model's retry override into the retry
+// package's config, falling back to the compiled-in default.
+func retryConfigFor(cfg config.ModelConfig) retry.Config {
+	rc := cfg.Retry
+	if rc == nil {
+		return retry.Default
+	}
+	out := retry.Config{
+		MaxAttempts: rc.MaxAttempts,
+		BaseDelay:   time.Duration(rc.BaseDelay),
+		MaxDelay:    time.Duration(rc.MaxDelay),
+		JitterRatio: rc.JitterRatio,
+	}
+	if out.BaseDelay == 0 {
+		out.BaseDelay = retry.Default.BaseDelay
+	}
+	if out.MaxDelay == 0 {
+		out.MaxDelay = retry.Default.MaxDelay
+	}
+	return out
+}
+
+// clientCacheKey identifies the client a model config needs, including the
+// extra headers so models behind different gateways get distinct clients.
+func clientCacheKey(cfg config.ModelConfig) string {
+	headerKeys := make([]string, 0, len(cfg.Headers))
+	for k := range cfg.Headers {
+		headerKeys = append(headerKeys, k+"="+cfg.Headers[k])
+	}
+	sort.Strings(headerKeys)
+	key := cfg.Provider + "\x00" + cfg.BaseURL + "\x00" + strings.Join(cfg.Keys(), ",") + "\x00" + strings.Join(headerKeys, ";")
+	if cfg.Retry != nil {
+		key += fmt.Sprintf("\x00retry:%d:%d:%d:%v", cfg.Retry.MaxAttempts, cfg.Retry.BaseDelay, cfg.Retry.MaxDelay, cfg.Retry.JitterRatio)
+	}
+	return key
+}
+
+// buildToolDefs reflects each tool's parameters into definitions. Under
+// FailFast any reflection failure aborts; under SkipBrokenTools the broken
+// tool is omitted (and excluded from execution) with its key returned in
+// skipped.
+func (r *Router) buildToolDefs(tools []Tool, policy ToolDefErrorPolicy) (defs []core.ToolDef, skipped []string, err error) {
+	if len(tools) == 0 {
+		return nil, nil, nil
+	}
+	defs = make([]core.ToolDef, 0, len(tools))
+	for _, t := range tools {
+		// Tools carrying a hand-written JSON Schema bypass reflection.
+		if rs, ok := t.(interface{ JSONSchema() string }); ok {
+			var schema map[string]any
+			if perr := json.Unmarshal([]byte(rs.JSONSchema()), &schema); perr != nil {
+				if policy == SkipBrokenTools {
+					r.logger.Warn("skipping tool with invalid raw schema", "tool", t.Name(), "error", perr)
+					skipped = append(skipped, t.Name())
+					continue
+				}
+				return nil, nil, fmt.Errorf("tool %s: invalid raw schema: %w", t.Name(), perr)
+			}
+			defs = append(defs, core.ToolDef{
+				Name:        t.Name(),
+				Description: t.Description(),
+				RawSchema:   schema,
+			})
+			continue
+		}
+		params, perr := util.CachedToolParameters(t.Parameters())
+		if perr != nil {
+			if policy == SkipBrokenTools {
+				r.logger.Warn("skipping tool with unreflectable parameters",
+					"tool", t.Name(), "error", perr)
+				skipped = append(skipped, t.Name())
+				continue
+			}
+			return nil, nil, fmt.Errorf("tool %s: %w", t.Name(), perr)
+		}
+		if o, ok := t.(interface {
+			applyOverrides([]core.ToolParameter) []core.ToolParameter
+		}); ok {
+			params = o.applyOverrides(params)
+		}
+		defs = append(defs, core.ToolDef{
+			Name:        t.Name(),
+			Description: t.Description(),
+			Parameters:  params,
+		})
+	}
+	return defs, skipped, nil
+}
+
+// applyGenerationDefaults fills unset (nil) sampling fields from the
+// model's configured defaults. A set request value — explicit zero
+// included — always wins.
+func applyGenerationDefaults(req *Request, d *config.GenerationDefaults) {
+	if d == nil {
+		return
+	}
+	if req.Temperature == nil {
+		req.Temperature = d.Temperature
+	}
+	if req.TopP == nil {
+		req.TopP = d.TopP
+	}
+	if req.TopK == nil {
+		req.TopK = d.TopK
+	}
+	if req.MaxTokens == nil {
+		req.MaxTokens = d.MaxTokens
+	}
+}
+
+// orZero dereferences an optional numeric field, with zero for unset.
+func orZero[T int | float64](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// requestHasFiles reports whether any message carries a document
+// attachment.
+func requestHasFiles(req Request) bool {
+	for _, m := range req.Messages {
+		if len(m.Files) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// requestHasAudio reports whether any message carries audio input.
+func requestHasAudio(req Request) bool {
+	for _, m := range req.Messages {
+		if len(m.Audio) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAllTags(cfg config.ModelConfig, tags []string) bool {
+	for _, tag := range tags {
+		if !cfg.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUnknownField reports whether any violation is a misspelled field.
+func hasUnknownField(vs []util.Violation) bool {
+	for _, v := range vs {
+		if v.Code == "unknown_field" {
+			return true
+		}
+	}
+	return false
+}
+
+// toolNamePattern is what both providers accept for function names; names
+// outside it draw cryptic 400s.
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// validateTools rejects tool sets the providers would refuse — duplicate
+// names, names with reserved characters, empty descriptions — before the
+// first provider call, naming the offending tool.
+func validateTools(tools []Tool) error {
+	seen := map[string]bool{}
+	for _, t := range tools {
+		name := t.Name()
+		if !toolNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: name %q must match %s", ErrInvalidTool, name, toolNamePattern)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidTool, name)
+		}
+		seen[name] = true
+		if strings.TrimSpace(t.Description()) == "" {
+			return fmt.Errorf("%w: %q has no description", ErrInvalidTool, name)
+		}
+	}
+	return nil
+}
+
+// toolRequiresApproval reports whether a tool should pass through the
+// approval callback. Tools opt out by implementing RequiresApproval and
+// returning false; anything else is treated as requiring approval, since
+// the conservative default is to ask.
+func toolRequiresApproval(tool Tool) bool {
+	if ra, ok := tool.(interface{ RequiresApproval() bool }); ok {
+		return ra.RequiresApproval()
+	}
+	return true
+}
+
+func findTool(tools []Tool, name string) Tool {
+	for _, t := range tools {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func findToolDef(defs []core.ToolDef, name string) core.ToolDef {
+	for _, d := range defs {
+		if d.Name == name {
+			return d
+		}
+	}
+	return core.ToolDef{Name: name}
+}
+
+// decodeArgs unmarshals raw model-supplied arguments into a fresh instance of
+// the tool's Parameters type.
+func decodeArgs(tool Tool, raw json.RawMessage) (any, error) {
+	proto := tool.Parameters()
+	t := reflect.TypeOf(proto)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,242 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// fakeClient replays scripted responses and records every CallParams it saw.
+type fakeClient struct {
+	responses []*core.RawResponse
+	errs      []error
+	calls     []core.CallParams
+}
+
+func (f *fakeClient) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	i := len(f.calls)
+	f.calls = append(f.calls, p)
+	if i < len(f.errs) && f.errs[i] != nil {
+		return nil, f.errs[i]
+	}
+	if i >= len(f.responses) {
+		return nil, fmt.Errorf("fakeClient: unexpected call %d", i)
+	}
+	return f.responses[i], nil
+}
+
+func testModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"fake": {
+			Provider:                 "test",
+			Model:                    "fake-model",
+			SupportsTools:            true,
+			SupportsStructuredOutput: true,
+		},
+	}
+}
+
+// newTestRouter builds a Router whose "test" provider is served by fake.
+func newTestRouter(t *testing.T, models map[string]config.ModelConfig, fake core.RawClient, opts ...Option) *Router {
+	t.Helper()
+	r, err := NewRouter(&config.LLMConfig{Models: models}, opts...)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	r.clients[clientCacheKey(config.ModelConfig{Provider: "test"})] = fake
+	return r
+}
+
+func textResponse(content string) *core.RawResponse {
+	return &core.RawResponse{Content: content, Usage: core.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}
+}
+
+func toolCallResponse(name, args string) *core.RawResponse {
+	return &core.RawResponse{
+		ToolCalls: []core.ToolCall{{CallID: "call_1", Name: name, Args: json.RawMessage(args)}},
+		Usage:     core.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+}
+
+type echoArgs struct {
+	Text string `json:"text" description:"text to echo"`
+}
+
+type echoTool struct{ executed int }
+
+func (e *echoTool) Name() string        { return "echo" }
+func (e *echoTool) Description() string { return "Echoes the provided text" }
+func (e *echoTool) Parameters() any     { return &echoArgs{} }
+func (e *echoTool) Execute(ctx context.Context, args any) (any, error) {
+	e.executed++
+	return map[string]string{"echoed": args.(*echoArgs).Text}, nil
+}
+
+func userRequest(prompt string) Request {
+	return Request{
+		Model:    "fake",
+		Messages: []Message{{Role: RoleUser, Content: prompt}},
+	}
+}
+
+func TestOnEventSequence(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "hi"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	var events []ToolLoopEvent
+	req := userRequest("echo hi")
+	req.Tools = []Tool{&echoTool{}}
+	req.OnEvent = func(ev ToolLoopEvent) { events = append(events, ev) }
+
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExecuteRaw: %v", err)
+	}
+	if out != "done" {
+		t.Errorf("content = %q, want %q", out, "done")
+	}
+
+	wantTypes := []ToolLoopEventType{
+		EventModelTurnStarted,
+		EventToolCallRequested,
+		EventToolExecuted,
+		EventModelTurnStarted,
+		EventFinalAnswer,
+	}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("got %d events %v, want %d", len(events), events, len(wantTypes))
+	}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %d = %s, want %s", i, events[i].Type, want)
+		}
+	}
+	if events[1].ToolName != "echo" || string(events[1].Args) != `{"text": "hi"}` {
+		t.Errorf("tool_call_requested carried %q %s", events[1].ToolName, events[1].Args)
+	}
+	if events[2].ResultSize == 0 {
+		t.Error("tool_executed should report serialized result size")
+	}
+	if events[4].Content != "done" {
+		t.Errorf("final_answer content = %q", events[4].Content)
+	}
+	if events[0].Turn != 0 || events[3].Turn != 1 {
+		t.Errorf("turn numbering wrong: %d, %d", events[0].Turn, events[3].Turn)
+	}
+}
+
+func TestOnEventNilCallback(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+	if _, err := r.ExecuteRaw(context.Background(), userRequest("hi")); err != nil {
+		t.Fatalf("ExecuteRaw with nil OnEvent: %v", err)
+	}
+}
+
+func TestSyntheticCallIDsForParallelCalls(t *testing.T) {
+	// Two parallel calls to the same tool with no provider IDs (Gemini).
+	fake := &fakeClient{responses: []*core.RawResponse{
+		{
+			ToolCalls: []core.ToolCall{
+				{Name: "echo", Args: json.RawMessage(`{"text":"first"}`)},
+				{Name: "echo", Args: json.RawMessage(`{"text":"second"}`)},
+			},
+			Usage: core.Usage{TotalTokens: 10},
+		},
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.CaptureTranscript = true
+
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := meta.Transcript[1].ToolCalls
+	results := meta.Transcript[2].ToolResults
+	if calls[0].CallID != "call_0_0" || calls[1].CallID != "call_0_1" {
+		t.Errorf("synthesized IDs = %q, %q", calls[0].CallID, calls[1].CallID)
+	}
+	if results[0].CallID != calls[0].CallID || results[1].CallID != calls[1].CallID {
+		t.Errorf("pairing broken: calls=%+v results=%+v", calls, results)
+	}
+	if c0, c1 := results[0].Content.(map[string]string)["echoed"], results[1].Content.(map[string]string)["echoed"]; c0 != "first" || c1 != "second" {
+		t.Errorf("results out of order: %v, %v", c0, c1)
+	}
+}
+
+func TestToolChoiceReachesProvider(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	req.ToolChoice = ToolChoiceTool("echo")
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	got := fake.calls[0].ToolChoice
+	if got.Mode != "tool" || got.Name != "echo" {
+		t.Errorf("CallParams.ToolChoice = %+v, want the request's choice wired through", got)
+	}
+}
+
+func TestStrictJSONSchemaCapabilityWired(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"modern": {Provider: "test", Model: "m", SupportsStructuredOutput: true, SupportsJSONSchema: true},
+		"legacy": {Provider: "test", Model: "l", SupportsStructuredOutput: true},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items":["a"]}`), textResponse(`{"items":["a"]}`),
+	}}
+	r := newTestRouter(t, models, fake)
+
+	if _, err := Execute[itemList](context.Background(), r, Request{Model: "modern", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.calls[0].StrictJSONSchema {
+		t.Error("supports_json_schema model should request strict schema enforcement")
+	}
+	if _, err := Execute[itemList](context.Background(), r, Request{Model: "legacy", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if fake.calls[1].StrictJSONSchema {
+		t.Error("model without the capability must keep the json_object path")
+	}
+}
+
+func TestStrictToolsWiredWithCapabilityFallback(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"modern": {Provider: "test", Model: "m", SupportsTools: true, SupportsJSONSchema: true},
+		"legacy": {Provider: "test", Model: "l", SupportsTools: true},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok"), textResponse("ok")}}
+	r := newTestRouter(t, models, fake)
+
+	req := Request{Model: "modern", StrictTools: true,
+		Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []Tool{&echoTool{}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.calls[0].StrictTools {
+		t.Error("StrictTools should reach the provider for capable models")
+	}
+
+	// Models that reject strict mode fall back gracefully.
+	req.Model = "legacy"
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if fake.calls[1].StrictTools {
+		t.Error("StrictTools must be dropped for models without the capability")
+	}
+}
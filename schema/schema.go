@@ -0,0 +1,20 @@
+// Package schema exposes the router's schema-sanitization rules so
+// applications accepting user-supplied JSON Schemas (extraction templates
+// and the like) can validate them at upload time with exactly the rules the
+// router will apply at request time.
+package schema
+
+import "github.com/LizzyG/llmrouter/internal/util"
+
+// Warning records one change sanitization had to make: a $ref inlined, an
+// unsupported keyword removed, the root coerced to an object.
+type Warning = util.SchemaWarning
+
+// SanitizeResponseSchema makes a JSON Schema safe for provider
+// structured-output modes and reports everything it changed. It returns an
+// error for schemas that cannot be made provider-safe: invalid JSON,
+// non-local or unresolvable $refs, and circular references. The returned
+// schema is byte-for-byte what the router would send.
+func SanitizeResponseSchema(schema string) (string, []Warning, error) {
+	return util.SanitizeResponseSchemaDetailed(schema)
+}
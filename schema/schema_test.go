@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSanitizeResponseSchemaGolden pins the sanitized output for a corpus of
+// gnarly schemas. Output is deterministic (json.Marshal sorts map keys), so
+// exact comparison is safe.
+func TestSanitizeResponseSchemaGolden(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           string
+		want         string
+		wantWarnings int
+	}{
+		{
+			name: "meta keywords stripped at root only",
+			in: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "https://example.com/x",
+				"title": "Root", "type": "object",
+				"properties": {"name": {"type": "string", "title": "Name", "description": "the name"}}}`,
+			want:         `{"properties":{"name":{"description":"the name","title":"Name","type":"string"}},"type":"object"}`,
+			wantWarnings: 3,
+		},
+		{
+			name: "local ref inlined and defs dropped",
+			in: `{"type": "object",
+				"properties": {"address": {"$ref": "#/$defs/Address"}},
+				"$defs": {"Address": {"type": "object", "properties": {"city": {"type": "string"}}}}}`,
+			want:         `{"properties":{"address":{"properties":{"city":{"type":"string"}},"type":"object"}},"type":"object"}`,
+			wantWarnings: 2,
+		},
+		{
+			name: "definitions section also resolvable",
+			in: `{"type": "object",
+				"properties": {"a": {"$ref": "#/definitions/A"}},
+				"definitions": {"A": {"type": "integer"}}}`,
+			want:         `{"properties":{"a":{"type":"integer"}},"type":"object"}`,
+			wantWarnings: 2,
+		},
+		{
+			name: "unsupported keywords removed everywhere",
+			in: `{"type": "object", "$comment": "internal",
+				"properties": {"v": {"type": "string", "not": {"const": "x"}, "contentEncoding": "base64"}},
+				"allOf": [{"required": ["v"]}]}`,
+			want:         `{"properties":{"v":{"type":"string"}},"type":"object"}`,
+			wantWarnings: 4,
+		},
+		{
+			name:         "missing root type coerced to object",
+			in:           `{"properties": {"n": {"type": "number"}}}`,
+			want:         `{"properties":{"n":{"type":"number"}},"type":"object"}`,
+			wantWarnings: 1,
+		},
+		{
+			name:         "shared definition inlined at each use",
+			in:           `{"type":"object","properties":{"a":{"$ref":"#/$defs/S"},"b":{"$ref":"#/$defs/S"}},"$defs":{"S":{"type":"string"}}}`,
+			want:         `{"properties":{"a":{"type":"string"},"b":{"type":"string"}},"type":"object"}`,
+			wantWarnings: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := SanitizeResponseSchema(tt.in)
+			if err != nil {
+				t.Fatalf("SanitizeResponseSchema: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sanitized =\n%s\nwant\n%s", got, tt.want)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %d (%v), want %d", len(warnings), warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestSanitizeResponseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"invalid JSON", `{"type": `, "invalid schema JSON"},
+		{"non-local ref", `{"type":"object","properties":{"a":{"$ref":"https://example.com/s.json"}}}`, "non-local $ref"},
+		{"unresolvable ref", `{"type":"object","properties":{"a":{"$ref":"#/$defs/Missing"}}}`, "unknown definition"},
+		{
+			"circular ref",
+			`{"type":"object","properties":{"a":{"$ref":"#/$defs/A"}},"$defs":{"A":{"type":"object","properties":{"next":{"$ref":"#/$defs/A"}}}}}`,
+			"circular reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := SanitizeResponseSchema(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+// SecretResolver fetches credential material from an external store (Vault,
+// AWS Secrets Manager, ...). Config values that look like references —
+// anything with a scheme, e.g. "vault://kv/openai#key" — are passed to
+// Resolve instead of being used verbatim.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// secretTTL is how long resolved credentials are trusted before the next
+// call re-resolves them; auth failures re-resolve immediately.
+const secretTTL = 5 * time.Minute
+
+// secretResolveTimeout bounds a single Resolve call.
+const secretResolveTimeout = 10 * time.Second
+
+// WithSecretResolver installs a resolver for secret references in model
+// API keys. Resolution is lazy (first use, not load time), cached for a
+// TTL window, and retried after provider auth failures so rotated keys
+// pick up without a restart.
+func WithSecretResolver(resolver SecretResolver) Option {
+	return func(r *Router) { r.secretResolver = resolver }
+}
+
+// isSecretRef reports whether a configured key is a reference to resolve
+// rather than a literal credential.
+func isSecretRef(key string) bool {
+	return strings.Contains(key, "://")
+}
+
+// hasSecretRefs reports whether any of the model's keys need resolution.
+func hasSecretRefs(cfg config.ModelConfig) bool {
+	for _, k := range cfg.Keys() {
+		if isSecretRef(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSecrets returns a copy of cfg with secret references replaced by
+// resolved credentials.
+func (r *Router) resolveSecrets(cfg config.ModelConfig) (config.ModelConfig, error) {
+	if r.secretResolver == nil || !hasSecretRefs(cfg) {
+		return cfg, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+	resolved := make([]string, 0, len(cfg.Keys()))
+	for _, k := range cfg.Keys() {
+		if !isSecretRef(k) {
+			resolved = append(resolved, k)
+			continue
+		}
+		value, err := r.secretResolver.Resolve(ctx, k)
+		if err != nil {
+			return cfg, fmt.Errorf("llmrouter: resolving secret %q: %w", k, err)
+		}
+		resolved = append(resolved, value)
+	}
+	out := cfg
+	out.APIKeys = resolved
+	out.APIKey = ""
+	return out, nil
+}
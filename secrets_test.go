@@ -0,0 +1,69 @@
+package llmrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+// rotatingResolver hands out versioned secrets, counting resolutions.
+type rotatingResolver struct {
+	resolves atomic.Int32
+	current  atomic.Value // string
+}
+
+func (rr *rotatingResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	rr.resolves.Add(1)
+	return rr.current.Load().(string), nil
+}
+
+func TestSecretResolverLazyAndReresolvedOnAuthFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key-v2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+	defer srv.Close()
+
+	resolver := &rotatingResolver{}
+	resolver.current.Store("key-v1")
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gpt": {Provider: "openai", Model: "gpt-4o", APIKey: "vault://kv/openai#key", BaseURL: srv.URL},
+	}}
+	r, err := NewRouter(cfg, WithSecretResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing resolved at construction: resolution is lazy.
+	if resolver.resolves.Load() != 0 {
+		t.Errorf("resolved %d times before any call", resolver.resolves.Load())
+	}
+
+	// The store has rotated to v2 by the time the first call happens; the
+	// router resolves v1... the resolver already returns the stale key,
+	// the provider 401s, and the router re-resolves the fresh one.
+	req := Request{Model: "gpt", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	resolver.current.Store("key-v1") // first resolution yields a dead key
+	_, err = r.ExecuteRaw(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected auth failure while the resolver returns the dead key")
+	}
+	if resolver.resolves.Load() < 2 {
+		t.Errorf("auth failure should trigger re-resolution, got %d resolves", resolver.resolves.Load())
+	}
+
+	// Once the resolver serves the rotated key, calls succeed without a
+	// restart.
+	resolver.current.Store("key-v2")
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "ok" {
+		t.Errorf("out=%q err=%v (resolves=%d)", out, err, resolver.resolves.Load())
+	}
+}
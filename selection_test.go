@@ -0,0 +1,302 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func strategyModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"aaa-expensive": {Provider: "test", Model: "a", SupportsTools: true, InputCostPerMTok: 10, OutputCostPerMTok: 30},
+		"mmm-cheap":     {Provider: "test", Model: "m", SupportsTools: true, InputCostPerMTok: 0.1, OutputCostPerMTok: 0.4},
+		"zzz-mid":       {Provider: "test", Model: "z", SupportsTools: true, InputCostPerMTok: 1, OutputCostPerMTok: 2},
+	}
+}
+
+func TestDefaultSelectionIsAlphabetical(t *testing.T) {
+	r := newTestRouter(t, strategyModels(), &fakeClient{responses: []*core.RawResponse{textResponse("ok")}})
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "aaa-expensive" {
+		t.Errorf("default selection picked %q, want alphabetically first", meta.ModelKey)
+	}
+}
+
+func TestCheapestFirstSelection(t *testing.T) {
+	r := newTestRouter(t, strategyModels(), &fakeClient{responses: []*core.RawResponse{textResponse("ok")}},
+		WithSelectionStrategy(CheapestFirst))
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "mmm-cheap" {
+		t.Errorf("CheapestFirst picked %q, want mmm-cheap", meta.ModelKey)
+	}
+}
+
+func TestCheapestFirstTieBreaksAlphabetically(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"bbb": {Provider: "test", Model: "b", InputCostPerMTok: 1, OutputCostPerMTok: 1},
+		"aaa": {Provider: "test", Model: "a", InputCostPerMTok: 1, OutputCostPerMTok: 1},
+	}
+	r := newTestRouter(t, models, &fakeClient{responses: []*core.RawResponse{textResponse("ok")}},
+		WithSelectionStrategy(CheapestFirst))
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "aaa" {
+		t.Errorf("tie broke to %q, want alphabetical", meta.ModelKey)
+	}
+}
+
+func TestRequireTags(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-plain": {Provider: "test", Model: "a"},
+		"bbb-eu":    {Provider: "test", Model: "b", Tags: []string{"eu-hosted", "vision"}},
+	}
+	r := newTestRouter(t, models, &fakeClient{responses: []*core.RawResponse{textResponse("ok"), textResponse("ok")}})
+
+	// Auto-selection filters on tags.
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Messages:    []Message{{Role: RoleUser, Content: "hi"}},
+		RequireTags: []string{"eu-hosted"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "bbb-eu" {
+		t.Errorf("selected %q, want tag-bearing model", meta.ModelKey)
+	}
+
+	// An explicit model missing a required tag is rejected.
+	_, err = r.ExecuteRaw(context.Background(), Request{
+		Model:       "aaa-plain",
+		Messages:    []Message{{Role: RoleUser, Content: "hi"}},
+		RequireTags: []string{"eu-hosted"},
+	})
+	if err == nil {
+		t.Error("explicit model lacking the tag should be rejected")
+	}
+}
+
+func TestContextWindowAwareRouting(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-small": {Provider: "test", Model: "s", ContextWindow: 50},
+		"bbb-big":   {Provider: "test", Model: "b", ContextWindow: 100000},
+	}
+	r := newTestRouter(t, models, &fakeClient{responses: []*core.RawResponse{textResponse("ok")}})
+
+	longPrompt := Request{Messages: []Message{{Role: RoleUser, Content: strings.Repeat("x", 1000)}}}
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), longPrompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "bbb-big" {
+		t.Errorf("selected %q, want the model whose window fits", meta.ModelKey)
+	}
+
+	// Explicitly choosing the small model fails with the typed error
+	// naming the estimate and the limit.
+	longPrompt.Model = "aaa-small"
+	_, err = r.ExecuteRaw(context.Background(), longPrompt)
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("err = %v, want ErrContextWindowExceeded", err)
+	} else if !strings.Contains(err.Error(), "250") || !strings.Contains(err.Error(), "50") {
+		t.Errorf("error should include estimate and limit: %v", err)
+	}
+
+	// MaxTokens is part of the budget.
+	fits := Request{Model: "aaa-small", Messages: []Message{{Role: RoleUser, Content: "hi"}}, MaxTokens: Int(49)}
+	if _, err := r.EstimateCost(fits); err != nil {
+		t.Fatalf("selection for fitting request failed: %v", err)
+	}
+	tooMuch := fits
+	tooMuch.MaxTokens = Int(1000)
+	if _, err := r.EstimateCost(tooMuch); !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("err = %v, want MaxTokens counted against the window", err)
+	}
+}
+
+func TestRPMLimitEnforced(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"limited": {Provider: "test", Model: "m", RPMLimit: 2},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse("1"), textResponse("2"), textResponse("3"),
+	}}
+	r := newTestRouter(t, models, fake)
+
+	req := Request{Model: "limited", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := r.ExecuteRaw(ctx, req); err == nil {
+		t.Error("third call should block on the rate limiter until ctx expiry")
+	}
+}
+
+func TestDefaultModelFromConfig(t *testing.T) {
+	cfg := &config.LLMConfig{
+		Models: map[string]config.ModelConfig{
+			"aaa-test": {Provider: "test", Model: "a"},
+			"real":     {Provider: "test", Model: "r", SupportsTools: true},
+		},
+		DefaultModel: "real",
+	}
+	r, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clients[clientCacheKey(config.ModelConfig{Provider: "test"})] = &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "real" {
+		t.Errorf("empty model resolved to %q, want the configured default", meta.ModelKey)
+	}
+
+	// A default model lacking a required capability is an error, not a
+	// silent fall-through to auto-selection.
+	cfg.DefaultModel = "aaa-test"
+	r2, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2.clients[clientCacheKey(config.ModelConfig{Provider: "test"})] = &fakeClient{}
+	req := Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []Tool{&echoTool{}}}
+	if _, err := r2.ExecuteRaw(context.Background(), req); !errors.Is(err, ErrNoCapableModel) {
+		t.Errorf("err = %v, want capability validation of the default model", err)
+	}
+}
+
+func TestAutoSelectConsidersStructuredOutput(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"aaa-plain":  {Provider: "test", Model: "a", SupportsTools: true},
+		"bbb-schema": {Provider: "test", Model: "b", SupportsTools: true, SupportsStructuredOutput: true},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`{"items":["x"]}`)}}
+	r := newTestRouter(t, models, fake)
+
+	// Typed execution must skip the model that would silently drop the
+	// schema, including when tools are in play.
+	req := Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []Tool{&echoTool{}}}
+	out, meta, err := ExecuteWithMeta[itemList](context.Background(), r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "bbb-schema" {
+		t.Errorf("selected %q, want structured-capable model", meta.ModelKey)
+	}
+	if len(out.Items) != 1 {
+		t.Errorf("out = %+v", out)
+	}
+	if fake.calls[0].OutputSchema == "" {
+		t.Error("schema should reach the structured-capable model")
+	}
+
+	// Raw execution is unaffected by the filter.
+	fake2 := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r2 := newTestRouter(t, models, fake2)
+	_, meta2, err := r2.ExecuteRawWithMeta(context.Background(), Request{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta2.ModelKey != "aaa-plain" {
+		t.Errorf("raw selection = %q, want alphabetical first", meta2.ModelKey)
+	}
+}
+
+func TestExplicitModelStructuredSupport(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"plain": {Provider: "test", Model: "p"},
+	}
+
+	// Default: warn and proceed via repair.
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`{"items":["x"]}`)}}
+	r, buf := routerWithLogBuffer(t, models, fake)
+	if _, err := Execute[itemList](context.Background(), r, Request{Model: "plain", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "structured output") {
+		t.Errorf("expected warning, got:\n%s", buf.String())
+	}
+	if fake.calls[0].OutputSchema != "" {
+		t.Error("schema should be blanked for non-supporting model")
+	}
+
+	// Behind the option: typed error.
+	r2 := newTestRouter(t, models, &fakeClient{}, WithStrictStructuredSupport(true))
+	_, err := Execute[itemList](context.Background(), r2, Request{Model: "plain", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if !errors.Is(err, ErrNoCapableModel) {
+		t.Errorf("err = %v, want ErrNoCapableModel under strict option", err)
+	}
+}
+
+func TestGenerationDefaultsFromConfig(t *testing.T) {
+	temp, topP := 0.2, 0.9
+	maxTok := 512
+	models := map[string]config.ModelConfig{
+		"tuned": {Provider: "test", Model: "m", Defaults: &config.GenerationDefaults{
+			Temperature: &temp, TopP: &topP, MaxTokens: &maxTok,
+		}},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok"), textResponse("ok")}}
+	r := newTestRouter(t, models, fake)
+
+	// Unset request fields pick up the defaults.
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "tuned", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	p := fake.calls[0]
+	if orZero(p.Temperature) != 0.2 || orZero(p.TopP) != 0.9 || orZero(p.MaxTokens) != 512 {
+		t.Errorf("params = temp=%v topP=%v max=%v", orZero(p.Temperature), orZero(p.TopP), orZero(p.MaxTokens))
+	}
+
+	// Explicit request values win.
+	if _, err := r.ExecuteRaw(context.Background(), Request{
+		Model: "tuned", Temperature: Float64(0.7), MaxTokens: Int(64),
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	p = fake.calls[1]
+	if orZero(p.Temperature) != 0.7 || orZero(p.MaxTokens) != 64 || orZero(p.TopP) != 0.9 {
+		t.Errorf("params = temp=%v topP=%v max=%v", orZero(p.Temperature), orZero(p.TopP), orZero(p.MaxTokens))
+	}
+}
+
+func TestExplicitZeroWinsOverDefaults(t *testing.T) {
+	temp := 0.8
+	models := map[string]config.ModelConfig{
+		"tuned": {Provider: "test", Model: "m", Defaults: &config.GenerationDefaults{Temperature: &temp}},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, models, fake)
+
+	if _, err := r.ExecuteRaw(context.Background(), Request{
+		Model:       "tuned",
+		Temperature: Float64(0), // explicit greedy sampling
+		Messages:    []Message{{Role: RoleUser, Content: "hi"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got := fake.calls[0].Temperature
+	if got == nil || *got != 0 {
+		t.Errorf("temperature = %v, explicit zero must be set and beat the config default", got)
+	}
+}
@@ -0,0 +1,89 @@
+package llmrouter
+
+import (
+	"context"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// shadowTimeout bounds a shadow call independently of the primary
+// request's budget.
+const shadowTimeout = 60 * time.Second
+
+// ShadowReport is delivered to the shadow callback after a shadow call
+// completes, for offline comparison of a candidate model against
+// production traffic.
+type ShadowReport struct {
+	PrimaryModel string
+	ShadowModel  string
+	Content      string
+	Usage        Usage
+	Latency      time.Duration
+	Err          error
+}
+
+type shadowConfig struct {
+	primary    string
+	shadow     string
+	sampleRate float64
+	callback   func(ShadowReport)
+}
+
+// WithShadowModel mirrors a sampleRate fraction of requests resolved to
+// primaryKey onto shadowKey. The shadow call runs asynchronously with its
+// own time budget and never blocks or fails the primary path; its content,
+// usage, and latency are delivered to callback. Requests carrying tools
+// are not shadowed, since replaying them could re-run side effects.
+func WithShadowModel(primaryKey, shadowKey string, sampleRate float64, callback func(ShadowReport)) Option {
+	return func(r *Router) {
+		r.shadow = &shadowConfig{
+			primary:    primaryKey,
+			shadow:     shadowKey,
+			sampleRate: sampleRate,
+			callback:   callback,
+		}
+	}
+}
+
+// maybeShadow launches a shadow call for the request when sampling and
+// eligibility allow. Callers pass the resolved primary key.
+func (r *Router) maybeShadow(req Request, outputSchema, primaryKey string) {
+	s := r.shadow
+	if s == nil || s.callback == nil || primaryKey != s.primary || len(req.Tools) > 0 {
+		return
+	}
+	r.rngMu.Lock()
+	sampled := r.rng.Float64() < s.sampleRate
+	r.rngMu.Unlock()
+	if !sampled {
+		return
+	}
+	cfg, ok := r.snapshot().models[s.shadow]
+	if !ok {
+		r.logger.Warn("shadow model not configured", "shadow", s.shadow)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+		defer cancel()
+		shadowReq := req
+		shadowReq.Model = s.shadow
+		msgs := make([]core.Message, len(req.Messages))
+		copy(msgs, req.Messages)
+		start := time.Now()
+		res, err := r.executeOnModel(ctx, shadowReq, outputSchema, s.shadow, cfg, &msgs)
+		report := ShadowReport{
+			PrimaryModel: primaryKey,
+			ShadowModel:  s.shadow,
+			Latency:      time.Since(start),
+			Err:          err,
+		}
+		if res != nil {
+			report.Content = res.content
+			report.Usage = res.meta.Usage
+		}
+		s.callback(report)
+	}()
+}
@@ -0,0 +1,77 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func shadowSetup(t *testing.T, rate float64) (*Router, *multiModelClient, chan ShadowReport) {
+	t.Helper()
+	models := map[string]config.ModelConfig{
+		"prod": {Provider: "test", Model: "m-prod", SupportsTools: true},
+		"eval": {Provider: "test", Model: "m-eval", SupportsTools: true},
+	}
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-prod": {responses: []*core.RawResponse{textResponse("primary answer"), textResponse("primary answer")}},
+		"m-eval": {responses: []*core.RawResponse{textResponse("shadow answer")}},
+	}}
+	reports := make(chan ShadowReport, 1)
+	r := newTestRouter(t, models, mm,
+		WithShadowModel("prod", "eval", rate, func(rep ShadowReport) { reports <- rep }))
+	return r, mm, reports
+}
+
+func TestShadowTrafficDelivered(t *testing.T) {
+	r, _, reports := shadowSetup(t, 1.0)
+	out, err := r.ExecuteRaw(context.Background(), Request{Model: "prod", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err != nil || out != "primary answer" {
+		t.Fatalf("primary: %q, %v", out, err)
+	}
+	select {
+	case rep := <-reports:
+		if rep.ShadowModel != "eval" || rep.Content != "shadow answer" || rep.Err != nil {
+			t.Errorf("report = %+v", rep)
+		}
+		if rep.Usage.TotalTokens == 0 || rep.Latency <= 0 {
+			t.Errorf("report missing usage/latency: %+v", rep)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("shadow report never delivered")
+	}
+}
+
+func TestShadowSkippedForToolRequests(t *testing.T) {
+	r, mm, reports := shadowSetup(t, 1.0)
+	mm.perModel["m-prod"] = &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`), textResponse("done"),
+	}}
+	_, err := r.ExecuteRaw(context.Background(), Request{
+		Model:    "prod",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+		Tools:    []Tool{&echoTool{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rep := <-reports:
+		t.Errorf("tool-bearing request was shadowed: %+v", rep)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestShadowZeroRateNeverFires(t *testing.T) {
+	r, _, reports := shadowSetup(t, 0)
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "prod", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reports:
+		t.Error("sampleRate 0 should never shadow")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,117 @@
+package llmrouter
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func splitModels() map[string]config.ModelConfig {
+	return map[string]config.ModelConfig{
+		"canary":       {Split: map[string]int{"stable": 80, "experimental": 20}},
+		"stable":       {Provider: "test", Model: "m-stable", SupportsTools: true},
+		"experimental": {Provider: "test", Model: "m-exp"},
+	}
+}
+
+func TestSplitDistribution(t *testing.T) {
+	r := newTestRouter(t, splitModels(), &fakeClient{})
+	r.rng = rand.New(rand.NewSource(7))
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		key, err := r.ModelFor(Request{Model: "canary", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[key]++
+	}
+	if counts["stable"] < 700 || counts["stable"] > 900 {
+		t.Errorf("distribution off: %v", counts)
+	}
+	if counts["experimental"] == 0 {
+		t.Errorf("experimental never chosen: %v", counts)
+	}
+}
+
+func TestSplitSeedableDeterminism(t *testing.T) {
+	pick := func(seed int64) []string {
+		r := newTestRouter(t, splitModels(), &fakeClient{})
+		r.rng = rand.New(rand.NewSource(seed))
+		var out []string
+		for i := 0; i < 10; i++ {
+			key, _ := r.ModelFor(Request{Model: "canary"})
+			out = append(out, key)
+		}
+		return out
+	}
+	a, b := pick(42), pick(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed diverged: %v vs %v", a, b)
+		}
+	}
+}
+
+func TestSplitRenormalizesOverEligible(t *testing.T) {
+	r := newTestRouter(t, splitModels(), &fakeClient{})
+	// Tools disqualify "experimental"; all traffic must go to "stable".
+	for i := 0; i < 50; i++ {
+		key, err := r.ModelFor(Request{Model: "canary", Tools: []Tool{&echoTool{}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "stable" {
+			t.Fatalf("tool request routed to %q", key)
+		}
+	}
+}
+
+func TestSplitChosenKeyInMetadata(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{
+		"m-stable": {responses: []*core.RawResponse{textResponse("ok")}},
+	}}
+	r := newTestRouter(t, splitModels(), mm)
+	r.rng = rand.New(rand.NewSource(1))
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), Request{
+		Model:    "canary",
+		Tools:    []Tool{&echoTool{}},
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ModelKey != "stable" {
+		t.Errorf("metadata reports %q, want the concrete member", meta.ModelKey)
+	}
+}
+
+func TestSplitEntriesSkippedByHealthAndWarmup(t *testing.T) {
+	mm := &multiModelClient{perModel: map[string]*fakeClient{}}
+	r := newTestRouter(t, splitModels(), mm)
+
+	// Warmup over all models must not abort on the virtual entry.
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Errorf("Warmup: %v", err)
+	}
+
+	// PingAll must not report a phantom failure for the split key (the
+	// fake provider has no Pinger, so concrete members report nothing).
+	failures := r.PingAll(context.Background())
+	if _, ok := failures["canary"]; ok {
+		t.Errorf("PingAll reported the virtual split entry: %v", failures)
+	}
+
+	// Models() marks the virtual entry instead of listing it with an
+	// empty provider and model.
+	for _, info := range r.Models() {
+		if info.Key == "canary" {
+			if len(info.Split) != 2 || info.Split["stable"] != 80 {
+				t.Errorf("split info = %+v", info)
+			}
+		}
+	}
+}
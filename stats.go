@@ -0,0 +1,14 @@
+package llmrouter
+
+// RouterStats is a point-in-time snapshot of router activity for
+// monitoring.
+type RouterStats struct {
+	// InFlightCalls is the number of provider HTTP calls currently
+	// executing.
+	InFlightCalls int
+}
+
+// Stats returns a snapshot of current router activity.
+func (r *Router) Stats() RouterStats {
+	return RouterStats{InFlightCalls: int(r.inFlight.Load())}
+}
@@ -0,0 +1,148 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// Partial carries one progressively parsed value from ExecuteStreamTyped.
+// Intermediate values are best-effort: populated from however much valid
+// JSON has streamed so far. The final event has Complete set and holds the
+// fully parsed value (or Err when parsing ultimately failed).
+type Partial[T any] struct {
+	Value    T
+	Complete bool
+	Err      error
+}
+
+// ExecuteStreamTyped runs the request and emits progressively parsed values
+// of T as content streams from the provider, letting callers render long
+// lists as they are generated. The channel always ends with exactly one
+// Complete event, produced through the same repair/unmarshal path Execute
+// uses. Providers that cannot stream degrade to that single final event.
+func ExecuteStreamTyped[T any](ctx context.Context, r *Router, req Request) (<-chan Partial[T], error) {
+	var zero T
+	schema, err := util.CachedResponseSchema(zero)
+	if err != nil {
+		return nil, fmt.Errorf("llmrouter: generating schema: %w", err)
+	}
+
+	req.requireStructured = true
+	ch := make(chan Partial[T])
+	go func() {
+		defer close(ch)
+
+		// The final event must never strand this goroutine when the caller
+		// stops reading.
+		deliver := func(p Partial[T]) {
+			select {
+			case ch <- p:
+			case <-ctx.Done():
+			}
+		}
+
+		onDelta := func(accumulated string) {
+			candidate := util.CompletePartialJSON(accumulated)
+			var v T
+			if json.Unmarshal([]byte(candidate), &v) == nil && !reflect.DeepEqual(v, zero) {
+				select {
+				case ch <- Partial[T]{Value: v}:
+				case <-ctx.Done():
+				}
+			}
+		}
+
+		content, err := r.executeStreaming(ctx, req, schema, onDelta)
+		if err != nil {
+			deliver(Partial[T]{Complete: true, Err: err})
+			return
+		}
+		var out T
+		if uerr := json.Unmarshal([]byte(content), &out); uerr != nil {
+			repaired := util.RepairJSON(content)
+			if uerr = json.Unmarshal([]byte(repaired), &out); uerr != nil {
+				deliver(Partial[T]{Complete: true, Err: fmt.Errorf("%w: %s", ErrStructuredOutput, describeUnmarshalError(uerr))})
+				return
+			}
+		}
+		deliver(Partial[T]{Value: out, Complete: true})
+	}()
+	return ch, nil
+}
+
+// executeStreaming performs a single streamed model call when the resolved
+// provider supports it, invoking onDelta with the accumulated content after
+// each fragment. Otherwise it falls back to the regular execution path and
+// the caller sees only the final result. Tool loops are not streamed.
+//
+// Note: the direct streaming branch does not yet apply rate limiters,
+// metrics, usage accounting, or generation defaults; both built-in
+// providers currently take the fallback path, which does.
+func (r *Router) executeStreaming(ctx context.Context, req Request, outputSchema string, onDelta func(accumulated string)) (string, error) {
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	modelKey, cfg, err := r.selectModel(req)
+	if err != nil {
+		return "", err
+	}
+	client, err := r.getClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	sc, ok := client.(core.StreamingClient)
+	if !ok || len(req.Tools) > 0 {
+		res, err := r.executeInternal(ctx, req, outputSchema)
+		if err != nil {
+			return "", err
+		}
+		return res.content, nil
+	}
+
+	if outputSchema != "" && !cfg.SupportsStructuredOutput {
+		outputSchema = ""
+	}
+	params := core.CallParams{
+		Model:            cfg.Model,
+		System:           req.System,
+		Messages:         req.Messages,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		TopK:             req.TopK,
+		MaxTokens:        req.MaxTokens,
+		Seed:             req.Seed,
+		OutputSchema:     outputSchema,
+		ForceJSON:        req.ForceJSON,
+		StrictJSONSchema: cfg.SupportsJSONSchema,
+		ToolChoice:       req.ToolChoice,
+		Extra:            req.ProviderOptions[cfg.Provider],
+	}
+	if err := r.acquireCallSlot(ctx); err != nil {
+		return "", fmt.Errorf("model %s: %w", modelKey, err)
+	}
+	defer r.releaseCallSlot()
+	var accumulated string
+	resp, err := sc.CallStream(ctx, params, func(delta string) {
+		accumulated += delta
+		onDelta(accumulated)
+	})
+	if err != nil {
+		return "", fmt.Errorf("model %s: %w", modelKey, err)
+	}
+	r.logger.Info("llm call",
+		"provider", cfg.Provider,
+		"model", modelKey,
+		"streamed", true,
+		"prompt_tokens", resp.Usage.PromptTokens,
+		"completion_tokens", resp.Usage.CompletionTokens,
+	)
+	return resp.Content, nil
+}
@@ -0,0 +1,86 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// fakeStreamingClient emits scripted content fragments then a final response.
+type fakeStreamingClient struct {
+	fakeClient
+	deltas []string
+}
+
+func (f *fakeStreamingClient) CallStream(ctx context.Context, p core.CallParams, onDelta func(string)) (*core.RawResponse, error) {
+	var full string
+	for _, d := range f.deltas {
+		full += d
+		onDelta(d)
+	}
+	return textResponse(full), nil
+}
+
+type itemList struct {
+	Items []string `json:"items"`
+}
+
+func TestExecuteStreamTypedEmitsPartials(t *testing.T) {
+	fake := &fakeStreamingClient{deltas: []string{
+		`{"items": ["one"`,
+		`, "two"`,
+		`, "three"]}`,
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	ch, err := ExecuteStreamTyped[itemList](context.Background(), r, userRequest("list"))
+	if err != nil {
+		t.Fatalf("ExecuteStreamTyped: %v", err)
+	}
+	var partials []Partial[itemList]
+	for p := range ch {
+		partials = append(partials, p)
+	}
+	if len(partials) < 2 {
+		t.Fatalf("expected partial events before the final one, got %v", partials)
+	}
+	final := partials[len(partials)-1]
+	if !final.Complete || final.Err != nil {
+		t.Fatalf("final event = %+v", final)
+	}
+	if len(final.Value.Items) != 3 || final.Value.Items[2] != "three" {
+		t.Errorf("final value = %+v", final.Value)
+	}
+	first := partials[0]
+	if first.Complete || len(first.Value.Items) == 0 {
+		t.Errorf("first partial should carry a best-effort value, got %+v", first)
+	}
+	for _, p := range partials[:len(partials)-1] {
+		if p.Complete {
+			t.Error("only the last event may be Complete")
+		}
+	}
+}
+
+func TestExecuteStreamTypedDegradesWithoutStreaming(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items": ["only"]}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+
+	ch, err := ExecuteStreamTyped[itemList](context.Background(), r, userRequest("list"))
+	if err != nil {
+		t.Fatalf("ExecuteStreamTyped: %v", err)
+	}
+	var partials []Partial[itemList]
+	for p := range ch {
+		partials = append(partials, p)
+	}
+	if len(partials) != 1 {
+		t.Fatalf("non-streaming provider should emit exactly one event, got %d", len(partials))
+	}
+	if !partials[0].Complete || partials[0].Err != nil || len(partials[0].Value.Items) != 1 {
+		t.Errorf("final event = %+v", partials[0])
+	}
+}
@@ -0,0 +1,94 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// Tokenizer counts tokens in text. The default is a rough chars/4 estimator;
+// supply an exact implementation (e.g. tiktoken) with WithTokenizer when
+// budgeting decisions need precision.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// estimatorTokenizer is the built-in heuristic: roughly four characters per
+// token, which is close enough for budgeting English prompts.
+type estimatorTokenizer struct{}
+
+func (estimatorTokenizer) CountTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// WithTokenizer replaces the built-in token estimator used for CountTokens
+// and context-budget checks on providers without a native counting API.
+func WithTokenizer(tok Tokenizer) Option {
+	return func(r *Router) {
+		if tok != nil {
+			r.tokenizer = tok
+		}
+	}
+}
+
+// CountTokens reports how many prompt tokens the request would consume,
+// including tool definitions. Providers with a native counting endpoint
+// (Gemini) are asked directly; for the rest the router's tokenizer
+// estimates.
+func (r *Router) CountTokens(ctx context.Context, req Request) (int, error) {
+	_, cfg, err := r.selectModel(req)
+	if err != nil {
+		return 0, err
+	}
+	defs, _, err := r.buildToolDefs(req.Tools, req.ToolDefPolicy)
+	if err != nil {
+		return 0, err
+	}
+	params := core.CallParams{
+		Model:    cfg.Model,
+		System:   req.System,
+		Messages: req.Messages,
+		ToolDefs: defs,
+	}
+	client, err := r.getClient(cfg)
+	if err != nil {
+		return 0, err
+	}
+	if tc, ok := client.(core.TokenCounter); ok {
+		return tc.CountTokens(ctx, params)
+	}
+	return r.estimateTokens(params), nil
+}
+
+// estimateTokens applies the router's tokenizer to everything the provider
+// would see: the system prompt, message contents, and serialized tool
+// definitions.
+func (r *Router) estimateTokens(p core.CallParams) int {
+	total := r.tokenizer.CountTokens(p.System)
+	for _, m := range p.Messages {
+		total += r.tokenizer.CountTokens(m.Content)
+	}
+	for _, d := range p.ToolDefs {
+		total += r.tokenizer.CountTokens(d.Name + d.Description)
+		if b, err := json.Marshal(core.GenerateJSONSchemaFromToolDef(d)); err == nil {
+			total += r.tokenizer.CountTokens(string(b))
+		}
+	}
+	return total
+}
+
+// checkContextBudget enforces Request.FailIfOverContext before a call goes
+// out: the estimated prompt plus the requested output budget must fit the
+// model's configured context window.
+func (r *Router) checkContextBudget(req Request, contextWindow, prompt int) error {
+	if !req.FailIfOverContext || contextWindow <= 0 {
+		return nil
+	}
+	if prompt+orZero(req.MaxTokens) > contextWindow {
+		return fmt.Errorf("%w: estimated %d prompt tokens + %d max output > context window %d",
+			ErrContextWindowExceeded, prompt, orZero(req.MaxTokens), contextWindow)
+	}
+	return nil
+}
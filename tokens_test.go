@@ -0,0 +1,126 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestCountTokensUsesGeminiEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, ":countTokens") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		io.WriteString(w, `{"totalTokens": 42}`)
+	}))
+	defer srv.Close()
+
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gem": {Provider: "gemini", Model: "gemini-2.0-flash", APIKey: "k", BaseURL: srv.URL},
+	}}
+	r, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := r.CountTokens(context.Background(), Request{Model: "gem", Messages: []Message{{Role: RoleUser, Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("CountTokens: %v", err)
+	}
+	if n != 42 {
+		t.Errorf("CountTokens = %d, want 42", n)
+	}
+}
+
+func TestCountTokensEstimatorFallback(t *testing.T) {
+	r := newTestRouter(t, testModels(), &fakeClient{})
+	content := strings.Repeat("word ", 20) // 100 chars -> ~25 tokens
+	n, err := r.CountTokens(context.Background(), Request{Model: "fake", Messages: []Message{{Role: RoleUser, Content: content}}})
+	if err != nil {
+		t.Fatalf("CountTokens: %v", err)
+	}
+	if n != 25 {
+		t.Errorf("CountTokens = %d, want 25 from the chars/4 estimator", n)
+	}
+}
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func TestWithTokenizer(t *testing.T) {
+	r := newTestRouter(t, testModels(), &fakeClient{}, WithTokenizer(wordTokenizer{}))
+	n, err := r.CountTokens(context.Background(), Request{Model: "fake", Messages: []Message{{Role: RoleUser, Content: "one two three"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("CountTokens = %d, want 3 from custom tokenizer", n)
+	}
+}
+
+func TestFailIfOverContext(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"tiny": {Provider: "test", Model: "t", ContextWindow: 10},
+	}
+	r := newTestRouter(t, models, &fakeClient{responses: []*core.RawResponse{textResponse("ok")}})
+
+	req := Request{
+		Model:             "tiny",
+		Messages:          []Message{{Role: RoleUser, Content: strings.Repeat("x", 200)}},
+		FailIfOverContext: true,
+	}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("err = %v, want ErrContextWindowExceeded", err)
+	}
+
+	// A prompt that fits goes through.
+	req.Messages = []Message{{Role: RoleUser, Content: "hi"}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Errorf("small prompt should pass: %v", err)
+	}
+}
+
+// slowClient blocks until its context is cancelled.
+type slowClient struct{}
+
+func (slowClient) Call(ctx context.Context, p core.CallParams) (*core.RawResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPerModelTimeoutBoundsEachCall(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"slow": {Provider: "test", Model: "s", Timeout: config.Duration(30 * time.Millisecond)},
+	}
+	r := newTestRouter(t, models, slowClient{})
+	start := time.Now()
+	_, err := r.ExecuteRaw(context.Background(), Request{Model: "slow", Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err == nil {
+		t.Fatal("expected deadline error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("per-model timeout not applied, took %v", elapsed)
+	}
+
+	// The tighter Request.Timeout wins.
+	models["slow"] = config.ModelConfig{Provider: "test", Model: "s", Timeout: config.Duration(10 * time.Second)}
+	r = newTestRouter(t, models, slowClient{})
+	start = time.Now()
+	_, err = r.ExecuteRaw(context.Background(), Request{
+		Model:    "slow",
+		Timeout:  30 * time.Millisecond,
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("request timeout should win: err=%v elapsed=%v", err, time.Since(start))
+	}
+}
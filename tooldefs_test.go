@@ -0,0 +1,51 @@
+package llmrouter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// brokenTool has parameters that cannot be reflected into a schema.
+type brokenTool struct{}
+
+func (brokenTool) Name() string        { return "broken" }
+func (brokenTool) Description() string { return "has unreflectable parameters" }
+func (brokenTool) Parameters() any {
+	return &struct {
+		Ch chan int `json:"ch"`
+	}{}
+}
+func (brokenTool) Execute(ctx context.Context, args any) (any, error) { return nil, nil }
+
+func TestBrokenToolFailsFastByDefault(t *testing.T) {
+	r := newTestRouter(t, testModels(), &fakeClient{})
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}, brokenTool{}}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("err = %v, want reflection failure naming the tool", err)
+	}
+}
+
+func TestBrokenToolSkippedUnderPolicy(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}, brokenTool{}}
+	req.ToolDefPolicy = SkipBrokenTools
+
+	_, meta, err := r.ExecuteRawWithMeta(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExecuteRawWithMeta: %v", err)
+	}
+	if len(meta.SkippedTools) != 1 || meta.SkippedTools[0] != "broken" {
+		t.Errorf("SkippedTools = %v", meta.SkippedTools)
+	}
+	defs := fake.calls[0].ToolDefs
+	if len(defs) != 1 || defs[0].Name != "echo" {
+		t.Errorf("provider saw defs %+v, want only the good tool", defs)
+	}
+}
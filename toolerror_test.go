@@ -0,0 +1,200 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// failingTool always errors.
+type failingTool struct{ calls int }
+
+func (f *failingTool) Name() string        { return "flaky" }
+func (f *failingTool) Description() string { return "Always fails" }
+func (f *failingTool) Parameters() any     { return &echoArgs{} }
+func (f *failingTool) Execute(ctx context.Context, args any) (any, error) {
+	f.calls++
+	return nil, fmt.Errorf("downstream unavailable")
+}
+
+func TestToolErrorAbortsByDefault(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{toolCallResponse("flaky", `{"text":"x"}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&failingTool{}}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "downstream unavailable") {
+		t.Errorf("err = %v, want abort with the tool error", err)
+	}
+}
+
+func TestToolErrorReportedToModel(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("flaky", `{"text":"x"}`),
+		textResponse("answered without the tool"),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&failingTool{}}
+	req.ToolErrorMode = ToolErrorReport
+
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "answered without the tool" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	// The second call must have carried the error result back to the model.
+	last := fake.calls[1].Messages
+	found := false
+	for _, m := range last {
+		for _, tr := range m.ToolResults {
+			if c, ok := tr.Content.(map[string]any); ok && strings.Contains(fmt.Sprint(c["error"]), "downstream unavailable") {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("error result not fed back: %+v", last)
+	}
+}
+
+func TestConsecutiveToolErrorsCapped(t *testing.T) {
+	// The model keeps retrying the failing tool forever.
+	var responses []*core.RawResponse
+	for i := 0; i < 8; i++ {
+		responses = append(responses, toolCallResponse("flaky", `{"text":"x"}`))
+	}
+	fake := &fakeClient{responses: responses}
+	r := newTestRouter(t, testModels(), fake)
+	tool := &failingTool{}
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	req.ToolErrorMode = ToolErrorReport
+
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "consecutive") {
+		t.Fatalf("err = %v, want consecutive-errors abort", err)
+	}
+	if !errors.Is(err, errUnwrapTarget(err)) { // sanity: wrapped chain intact
+		t.Error("underlying error should be wrapped")
+	}
+	if tool.calls != maxConsecutiveToolErrorTurns {
+		t.Errorf("tool ran %d times, want cap of %d", tool.calls, maxConsecutiveToolErrorTurns)
+	}
+}
+
+// errUnwrapTarget digs out the innermost error for the wrap sanity check.
+func errUnwrapTarget(err error) error {
+	for {
+		u := errors.Unwrap(err)
+		if u == nil {
+			return err
+		}
+		err = u
+	}
+}
+
+func TestArgValidationFollowsPolicy(t *testing.T) {
+	// Abort mode: the typed error surfaces with the violation details.
+	fake := &fakeClient{responses: []*core.RawResponse{toolCallResponse("echo", `{"wrong": 1}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	_, err := r.ExecuteRaw(context.Background(), req)
+	if !errors.Is(err, ErrInvalidToolArgs) || !strings.Contains(err.Error(), "text") {
+		t.Errorf("err = %v, want ErrInvalidToolArgs naming the missing field", err)
+	}
+
+	// Report mode: the model gets the violations and can correct itself.
+	fake = &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"wrong": 1}`),
+		toolCallResponse("echo", `{"text": "fixed"}`),
+		textResponse("done"),
+	}}
+	tool := &echoTool{}
+	r = newTestRouter(t, testModels(), fake)
+	req = userRequest("go")
+	req.Tools = []Tool{tool}
+	req.ToolErrorMode = ToolErrorReport
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" || tool.executed != 1 {
+		t.Errorf("out=%q executed=%d err=%v", out, tool.executed, err)
+	}
+}
+
+func TestArgValidationEnumMembership(t *testing.T) {
+	def := core.ToolDef{Name: "convert", Parameters: []core.ToolParameter{{
+		Name:     "unit",
+		Required: true,
+		Schema:   map[string]any{"type": "string", "enum": []any{"celsius", "fahrenheit"}},
+	}}}
+	vs := util.ValidateToolArgs([]byte(`{"unit": "kelvin"}`), def, false)
+	if len(vs) != 1 {
+		t.Fatalf("violations = %v", vs)
+	}
+	got := util.FormatViolations(vs)
+	if !strings.Contains(got, "kelvin") || !strings.Contains(got, "celsius") {
+		t.Errorf("formatted = %q", got)
+	}
+	if vs := util.ValidateToolArgs([]byte(`{"unit": "celsius"}`), def, false); len(vs) != 0 {
+		t.Errorf("valid enum value flagged: %v", vs)
+	}
+}
+
+func TestStrictArgsRecoversFromMisspelledFields(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"texxt": "hi"}`), // misspelled
+		toolCallResponse("echo", `{"text": "hi"}`),  // corrected next turn
+		textResponse("done"),
+	}}
+	tool := &echoTool{}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	req.StrictToolArgs = true
+
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if tool.executed != 1 {
+		t.Errorf("tool executed %d times, want once after correction", tool.executed)
+	}
+	// The corrective message lists the valid field names.
+	second := fake.calls[1].Messages
+	found := false
+	for _, m := range second {
+		for _, tr := range m.ToolResults {
+			if c, ok := tr.Content.(map[string]any); ok {
+				msg, _ := c["error"].(string)
+				if strings.Contains(msg, "texxt: unknown field") && strings.Contains(msg, "one of [text]") {
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		t.Errorf("corrective message missing: %+v", second)
+	}
+}
+
+func TestNonStrictArgsIgnoreUnknownFields(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text": "hi", "extra": 1}`),
+		textResponse("done"),
+	}}
+	tool := &echoTool{}
+	r := newTestRouter(t, testModels(), fake)
+	req := userRequest("go")
+	req.Tools = []Tool{tool}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if tool.executed != 1 {
+		t.Errorf("unknown fields without strict mode should be ignored; executed=%d", tool.executed)
+	}
+}
@@ -0,0 +1,100 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+type ctxKey string
+
+func TestToolHooksWrapExecution(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("ctxprobe", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+
+	var preInfo ToolCallInfo
+	var postResult any
+	var sawTenant string
+
+	probe := &ctxProbeTool{onExecute: func(ctx context.Context) {
+		sawTenant, _ = ctx.Value(ctxKey("tenant")).(string)
+	}}
+	r := newTestRouter(t, testModels(), fake, WithToolHook(
+		func(ctx context.Context, info ToolCallInfo) (context.Context, error) {
+			preInfo = info
+			return context.WithValue(ctx, ctxKey("tenant"), "acme"), nil
+		},
+		func(ctx context.Context, info ToolCallInfo, result any, err error) {
+			postResult = result
+			if err != nil {
+				t.Errorf("post hook err = %v", err)
+			}
+		},
+	))
+
+	req := userRequest("go")
+	req.Tools = []Tool{probe}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if preInfo.Name != "ctxprobe" || preInfo.CallID != "call_1" || preInfo.Turn != 0 {
+		t.Errorf("preInfo = %+v", preInfo)
+	}
+	if sawTenant != "acme" {
+		t.Error("pre-hook context did not reach the tool")
+	}
+	if m, ok := postResult.(map[string]string); !ok || m["echoed"] != "x" {
+		t.Errorf("post hook saw %v, want the exact serialized result", postResult)
+	}
+}
+
+type ctxProbeTool struct {
+	onExecute func(ctx context.Context)
+}
+
+func (c *ctxProbeTool) Name() string        { return "ctxprobe" }
+func (c *ctxProbeTool) Description() string { return "Observes its context" }
+func (c *ctxProbeTool) Parameters() any     { return &echoArgs{} }
+func (c *ctxProbeTool) Execute(ctx context.Context, args any) (any, error) {
+	c.onExecute(ctx)
+	return map[string]string{"echoed": args.(*echoArgs).Text}, nil
+}
+
+func TestPreHookErrorFollowsPolicy(t *testing.T) {
+	hookErr := errors.New("tenant not allowed")
+	pre := func(ctx context.Context, info ToolCallInfo) (context.Context, error) {
+		return nil, hookErr
+	}
+
+	// Abort mode: the request fails.
+	fake := &fakeClient{responses: []*core.RawResponse{toolCallResponse("echo", `{"text":"x"}`)}}
+	r := newTestRouter(t, testModels(), fake, WithToolHook(pre, nil))
+	req := userRequest("go")
+	req.Tools = []Tool{&echoTool{}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err == nil || !strings.Contains(err.Error(), "pre-hook") {
+		t.Errorf("err = %v", err)
+	}
+
+	// Report mode: the rejection is fed back and the loop continues.
+	fake = &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+	tool := &echoTool{}
+	r = newTestRouter(t, testModels(), fake, WithToolHook(pre, nil))
+	req = userRequest("go")
+	req.Tools = []Tool{tool}
+	req.ToolErrorMode = ToolErrorReport
+	out, err := r.ExecuteRaw(context.Background(), req)
+	if err != nil || out != "done" {
+		t.Fatalf("out=%q err=%v", out, err)
+	}
+	if tool.executed != 0 {
+		t.Error("tool must not run when the pre-hook rejects it")
+	}
+}
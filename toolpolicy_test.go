@@ -0,0 +1,54 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestPerModelToolDenyList(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"external": {Provider: "test", Model: "m", SupportsTools: true, DeniedTools: []string{"db_query"}},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, models, fake)
+
+	db := &namedTool{name: "db_query", desc: "Queries the internal database"}
+	req := Request{Model: "external", Messages: []Message{{Role: RoleUser, Content: "hi"}},
+		Tools: []Tool{&echoTool{}, db}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	defs := fake.calls[0].ToolDefs
+	if len(defs) != 1 || defs[0].Name != "echo" {
+		t.Errorf("defs = %+v, want denied tool filtered out", defs)
+	}
+
+	// A request whose only tool is denied fails rather than sending an
+	// empty tool list.
+	req.Tools = []Tool{db}
+	if _, err := r.ExecuteRaw(context.Background(), req); !errors.Is(err, ErrAllToolsDenied) {
+		t.Errorf("err = %v, want ErrAllToolsDenied", err)
+	}
+}
+
+func TestPerModelToolAllowList(t *testing.T) {
+	models := map[string]config.ModelConfig{
+		"locked": {Provider: "test", Model: "m", SupportsTools: true, AllowedTools: []string{"echo"}},
+	}
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, models, fake)
+
+	req := Request{Model: "locked", Messages: []Message{{Role: RoleUser, Content: "hi"}},
+		Tools: []Tool{&echoTool{}, &namedTool{name: "other", desc: "d"}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	defs := fake.calls[0].ToolDefs
+	if len(defs) != 1 || defs[0].Name != "echo" {
+		t.Errorf("defs = %+v, want only allow-listed tool", defs)
+	}
+}
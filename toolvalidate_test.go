@@ -0,0 +1,51 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// namedTool overrides just the identity of an echoTool.
+type namedTool struct {
+	echoTool
+	name, desc string
+}
+
+func (n *namedTool) Name() string        { return n.name }
+func (n *namedTool) Description() string { return n.desc }
+
+func TestToolValidationBeforeFirstCall(t *testing.T) {
+	fake := &fakeClient{}
+	r := newTestRouter(t, testModels(), fake)
+
+	cases := []struct {
+		name  string
+		tools []Tool
+		want  string
+	}{
+		{"duplicate names", []Tool{
+			&namedTool{name: "echo", desc: "one"},
+			&namedTool{name: "echo", desc: "two"},
+		}, "duplicate"},
+		{"reserved characters", []Tool{&namedTool{name: "my tool!", desc: "d"}}, "must match"},
+		{"unicode name", []Tool{&namedTool{name: "héllo", desc: "d"}}, "must match"},
+		{"empty name", []Tool{&namedTool{name: "", desc: "d"}}, "must match"},
+		{"too long", []Tool{&namedTool{name: strings.Repeat("a", 65), desc: "d"}}, "must match"},
+		{"empty description", []Tool{&namedTool{name: "fine_name", desc: "  "}}, "no description"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := userRequest("go")
+			req.Tools = tc.tools
+			_, err := r.ExecuteRaw(context.Background(), req)
+			if !errors.Is(err, ErrInvalidTool) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want ErrInvalidTool with %q", err, tc.want)
+			}
+		})
+	}
+	if len(fake.calls) != 0 {
+		t.Error("validation failures must occur before any provider call")
+	}
+}
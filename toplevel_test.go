@@ -0,0 +1,107 @@
+package llmrouter
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestExecuteTopLevelSlice(t *testing.T) {
+	// The model honors the envelope the schema asked for.
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`{"items": ["a", "b"]}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	out, err := Execute[[]string](context.Background(), r, userRequest("list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out[1] != "b" {
+		t.Errorf("out = %v", out)
+	}
+
+	// The schema sent to the provider has an object root wrapping the
+	// array.
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(fake.calls[0].OutputSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["type"] != "object" {
+		t.Errorf("root = %v", schema["type"])
+	}
+	items := schema["properties"].(map[string]any)["items"].(map[string]any)
+	if items["type"] != "array" {
+		t.Errorf("wrapped schema = %v", schema)
+	}
+
+	// A bare array answer (providers that allow array roots) also parses.
+	fake2 := &fakeClient{responses: []*core.RawResponse{textResponse(`["x", "y"]`)}}
+	r2 := newTestRouter(t, testModels(), fake2)
+	out, err = Execute[[]string](context.Background(), r2, userRequest("list"))
+	if err != nil || len(out) != 2 {
+		t.Errorf("bare array: out=%v err=%v", out, err)
+	}
+}
+
+func TestExecuteTopLevelSliceOfStructs(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"items": [{"name": "Ada"}, {"name": "Grace"}]}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	out, err := Execute[[]extraction](context.Background(), r, userRequest("extract"))
+	if err != nil || len(out) != 2 || out[1].Name != "Grace" {
+		t.Errorf("out=%v err=%v", out, err)
+	}
+}
+
+func TestExecuteTopLevelMap(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{
+		textResponse(`{"ada": 0.9, "grace": 0.8}`),
+	}}
+	r := newTestRouter(t, testModels(), fake)
+	out, err := Execute[map[string]float64](context.Background(), r, userRequest("scores"))
+	if err != nil || out["ada"] != 0.9 {
+		t.Errorf("out=%v err=%v", out, err)
+	}
+	// Maps are already object-rooted: no envelope.
+	if strings.Contains(fake.calls[0].OutputSchema, `"items"`) {
+		t.Errorf("map schema should not be wrapped: %s", fake.calls[0].OutputSchema)
+	}
+	if !strings.Contains(fake.calls[0].OutputSchema, `additionalProperties`) {
+		t.Errorf("schema = %s", fake.calls[0].OutputSchema)
+	}
+}
+
+func TestExecuteStringSkipsStructuredPath(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("plain prose, no quotes")}}
+	r := newTestRouter(t, testModels(), fake)
+	out, err := Execute[string](context.Background(), r, userRequest("write prose"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "plain prose, no quotes" {
+		t.Errorf("out = %q, want raw content untouched", out)
+	}
+	if fake.calls[0].OutputSchema != "" {
+		t.Errorf("string target must not send a response schema, got %s", fake.calls[0].OutputSchema)
+	}
+	if fake.calls[0].ForceJSON {
+		t.Error("string target must not force JSON mode")
+	}
+}
+
+func TestExecuteRawMessageSkipsStructuredPath(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse(`{"free": "form"}`)}}
+	r := newTestRouter(t, testModels(), fake)
+	out, err := Execute[json.RawMessage](context.Background(), r, userRequest("json please"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"free": "form"}` {
+		t.Errorf("out = %s", out)
+	}
+	if fake.calls[0].OutputSchema != "" {
+		t.Error("raw message target must not send a response schema")
+	}
+}
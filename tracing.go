@@ -0,0 +1,62 @@
+package llmrouter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// WithTracerProvider enables OpenTelemetry instrumentation: a span per
+// execution with child llm.call spans per model turn (carrying gen_ai.*
+// semantic-convention attributes) and llm.tool spans per tool execution.
+// Span context flows into Tool.Execute so tools can create their own
+// children. Without this option no tracing code runs.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(r *Router) {
+		r.tracer = tp.Tracer("github.com/LizzyG/llmrouter")
+	}
+}
+
+// startSpan opens a span when tracing is enabled; the returned end func is
+// a no-op otherwise.
+func (r *Router) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if r.tracer == nil {
+		return ctx, nil
+	}
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endCallSpan records the outcome of one model turn on its llm.call span.
+func endCallSpan(span trace.Span, resp *core.RawResponse, err error) {
+	if span == nil {
+		return
+	}
+	if resp != nil {
+		span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+			attribute.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+			attribute.StringSlice("gen_ai.response.finish_reasons", []string{string(resp.FinishReason)}),
+		)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// endSpan closes a span recording err, tolerating disabled tracing.
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
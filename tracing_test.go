@@ -0,0 +1,85 @@
+package llmrouter
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+// spanProbeTool records whether its context carried an active span.
+type spanProbeTool struct {
+	echoTool
+	hadSpan bool
+}
+
+func (s *spanProbeTool) Execute(ctx context.Context, args any) (any, error) {
+	s.hadSpan = trace.SpanContextFromContext(ctx).IsValid()
+	return s.echoTool.Execute(ctx, args)
+}
+
+func TestTracingSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	fake := &fakeClient{responses: []*core.RawResponse{
+		toolCallResponse("echo", `{"text":"x"}`),
+		textResponse("done"),
+	}}
+	r := newTestRouter(t, testModels(), fake, WithTracerProvider(tp))
+	probe := &spanProbeTool{}
+	req := userRequest("go")
+	req.Tools = []Tool{probe}
+
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if !probe.hadSpan {
+		t.Error("span context did not propagate into Tool.Execute")
+	}
+
+	spans := exporter.GetSpans()
+	byName := map[string][]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+	if len(byName["llmrouter.execute"]) != 1 {
+		t.Errorf("execute spans = %d", len(byName["llmrouter.execute"]))
+	}
+	if len(byName["llm.call"]) != 2 {
+		t.Errorf("llm.call spans = %d, want one per model turn", len(byName["llm.call"]))
+	}
+	if len(byName["llm.tool"]) != 1 {
+		t.Errorf("llm.tool spans = %d", len(byName["llm.tool"]))
+	}
+
+	call := byName["llm.call"][0]
+	attrs := map[string]any{}
+	for _, kv := range call.Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs["gen_ai.system"] != "test" || attrs["gen_ai.request.model"] != "fake-model" {
+		t.Errorf("call attrs = %v", attrs)
+	}
+	if attrs["gen_ai.usage.input_tokens"] != int64(10) || attrs["gen_ai.usage.output_tokens"] != int64(5) {
+		t.Errorf("usage attrs = %v", attrs)
+	}
+
+	// Child spans hang off the execute root.
+	root := byName["llmrouter.execute"][0]
+	if call.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Error("llm.call is not a child of the execute span")
+	}
+}
+
+func TestTracingDisabledByDefault(t *testing.T) {
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake)
+	if _, err := r.ExecuteRaw(context.Background(), userRequest("hi")); err != nil {
+		t.Fatal(err)
+	}
+}
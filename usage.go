@@ -0,0 +1,61 @@
+package llmrouter
+
+import "sync"
+
+// ModelUsage is the cumulative accounting for one model key since router
+// construction (or the last ResetUsage).
+type ModelUsage struct {
+	// Calls counts provider calls, including failed ones; Errors counts
+	// the failures among them.
+	Calls  int64
+	Errors int64
+
+	PromptTokens     int64
+	CompletionTokens int64
+	// Cost is the accumulated dollar cost from the model's configured
+	// rates.
+	Cost float64
+}
+
+// usageAccumulator aggregates per-model counters across goroutines.
+type usageAccumulator struct {
+	mu      sync.Mutex
+	byModel map[string]ModelUsage
+}
+
+func newUsageAccumulator() *usageAccumulator {
+	return &usageAccumulator{byModel: map[string]ModelUsage{}}
+}
+
+func (a *usageAccumulator) record(modelKey string, prompt, completion int, cost float64, failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u := a.byModel[modelKey]
+	u.Calls++
+	if failed {
+		u.Errors++
+	}
+	u.PromptTokens += int64(prompt)
+	u.CompletionTokens += int64(completion)
+	u.Cost += cost
+	a.byModel[modelKey] = u
+}
+
+// Usage returns a snapshot of cumulative usage per model key — basic
+// accounting for small apps without a metrics backend.
+func (r *Router) Usage() map[string]ModelUsage {
+	r.usage.mu.Lock()
+	defer r.usage.mu.Unlock()
+	out := make(map[string]ModelUsage, len(r.usage.byModel))
+	for k, v := range r.usage.byModel {
+		out[k] = v
+	}
+	return out
+}
+
+// ResetUsage clears the cumulative counters.
+func (r *Router) ResetUsage() {
+	r.usage.mu.Lock()
+	defer r.usage.mu.Unlock()
+	r.usage.byModel = map[string]ModelUsage{}
+}
@@ -0,0 +1,97 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+)
+
+func TestUsageAccumulator(t *testing.T) {
+	fake := &fakeClient{
+		responses: []*core.RawResponse{
+			toolCallResponse("echo", `{"text":"a"}`),
+			textResponse("done"),
+			nil,
+		},
+		errs: []error{nil, nil, errors.New("boom")},
+	}
+	r := newTestRouter(t, pricedModels(), fake)
+
+	req := Request{Model: "priced", Messages: []Message{{Role: RoleUser, Content: "hi"}}, Tools: []Tool{&echoTool{}}}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ExecuteRaw(context.Background(), Request{Model: "priced", Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err == nil {
+		t.Fatal("third call should fail")
+	}
+
+	u := r.Usage()["priced"]
+	if u.Calls != 3 || u.Errors != 1 {
+		t.Errorf("calls=%d errors=%d", u.Calls, u.Errors)
+	}
+	if u.PromptTokens != 20 || u.CompletionTokens != 10 {
+		t.Errorf("tokens = %+v", u)
+	}
+	if u.Cost <= 0 {
+		t.Errorf("cost = %v", u.Cost)
+	}
+
+	r.ResetUsage()
+	if len(r.Usage()) != 0 {
+		t.Error("ResetUsage did not clear counters")
+	}
+}
+
+func TestUsageCallbackPerRequest(t *testing.T) {
+	reports := make(chan UsageReport, 2)
+	fake := &fakeClient{
+		responses: []*core.RawResponse{textResponse("ok"), nil},
+		errs:      []error{nil, errors.New("boom")},
+	}
+	r := newTestRouter(t, pricedModels(), fake,
+		WithUsageCallback(func(ctx context.Context, rep UsageReport) { reports <- rep }))
+
+	req := Request{
+		Model:    "priced",
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+		Tags:     map[string]string{"tenant": "acme"},
+	}
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	rep := <-reports
+	if rep.ModelKey != "priced" || rep.Err != nil || rep.Tags["tenant"] != "acme" {
+		t.Errorf("report = %+v", rep)
+	}
+	if rep.Usage.TotalTokens != 15 || rep.Cost <= 0 || rep.Latency <= 0 {
+		t.Errorf("report accounting = %+v", rep)
+	}
+
+	// Failures are reported too.
+	if _, err := r.ExecuteRaw(context.Background(), req); err == nil {
+		t.Fatal("second call should fail")
+	}
+	rep = <-reports
+	if rep.Err == nil {
+		t.Errorf("failure report = %+v", rep)
+	}
+}
+
+func TestUsageCallbackContextOutlivesRequestTimeout(t *testing.T) {
+	reports := make(chan error, 1)
+	fake := &fakeClient{responses: []*core.RawResponse{textResponse("ok")}}
+	r := newTestRouter(t, testModels(), fake,
+		WithUsageCallback(func(ctx context.Context, rep UsageReport) { reports <- ctx.Err() }))
+
+	req := userRequest("hi")
+	req.Timeout = time.Minute
+	if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if ctxErr := <-reports; ctxErr != nil {
+		t.Errorf("usage callback received a dead context: %v", ctxErr)
+	}
+}
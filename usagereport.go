@@ -0,0 +1,54 @@
+package llmrouter
+
+import (
+	"context"
+	"time"
+)
+
+// UsageReport is delivered to the usage callback once per completed
+// request, success or failure — the event a billing pipeline consumes.
+type UsageReport struct {
+	// ModelKey and Provider identify who served (or failed) the request.
+	ModelKey string
+	Provider string
+	// Usage and Cost are aggregated across every turn, including retries.
+	Usage Usage
+	Cost  float64
+	// ToolTurns is how many turns ran tools.
+	ToolTurns int
+	// Latency is the whole request's wall time.
+	Latency time.Duration
+	// Err is the request's final error, nil on success.
+	Err error
+	// Tags echoes Request.Tags so callers can correlate the report with
+	// their own entities (tenant, feature, trace ID).
+	Tags map[string]string
+}
+
+// WithUsageCallback registers a callback invoked once at the end of every
+// execution, success or failure. The callback runs on its own goroutine so
+// it can never add latency to the request path; it must therefore be safe
+// for concurrent use.
+func WithUsageCallback(fn func(ctx context.Context, report UsageReport)) Option {
+	return func(r *Router) { r.usageCallback = fn }
+}
+
+// reportUsage assembles and dispatches the report asynchronously.
+func (r *Router) reportUsage(ctx context.Context, req Request, res *execResult, err error, started time.Time) {
+	if r.usageCallback == nil {
+		return
+	}
+	report := UsageReport{
+		Latency: time.Since(started),
+		Err:     err,
+		Tags:    req.Tags,
+	}
+	if res != nil {
+		report.ModelKey = res.meta.ModelKey
+		report.Provider = res.meta.Provider
+		report.Usage = res.meta.Usage
+		report.Cost = res.meta.Cost
+		report.ToolTurns = res.meta.ToolTurns
+	}
+	go r.usageCallback(ctx, report)
+}
@@ -0,0 +1,58 @@
+package llmrouter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LizzyG/llmrouter/internal/core"
+	"github.com/LizzyG/llmrouter/internal/util"
+)
+
+// Warmup pre-builds provider clients for the given model keys (all
+// configured models when none are named) and establishes their connections,
+// so the first real request doesn't pay for lazy construction and TLS
+// handshakes. It is time-boxed by ctx: pass a context with a deadline to cap
+// how long warm-up may take. Connection warm-up failures are logged, not
+// returned — a cold connection is not an error.
+func (r *Router) Warmup(ctx context.Context, models ...string) error {
+	if len(models) == 0 {
+		for k := range r.snapshot().models {
+			models = append(models, k)
+		}
+	}
+	for _, key := range models {
+		cfg, ok := r.snapshot().models[key]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownModel, key)
+		}
+		// Virtual split entries have no provider of their own; their
+		// members are warmed under their own keys.
+		if len(cfg.Split) > 0 {
+			continue
+		}
+		client, err := r.getClient(cfg)
+		if err != nil {
+			return err
+		}
+		if wc, ok := client.(core.WarmableClient); ok {
+			if err := wc.Warm(ctx); err != nil {
+				r.logger.Warn("connection warm-up failed", "model", key, "error", err)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WarmupTypes pre-generates and caches the response schema for T, paying
+// the reflection and sanitization cost at warm-up time instead of on the
+// first Execute[T] call.
+func WarmupTypes[T any]() error {
+	var zero T
+	if _, err := util.CachedResponseSchema(zero); err != nil {
+		return fmt.Errorf("llmrouter: generating schema: %w", err)
+	}
+	return nil
+}
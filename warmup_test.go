@@ -0,0 +1,92 @@
+package llmrouter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LizzyG/llmrouter/internal/config"
+)
+
+func fakeOpenAIServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			io.WriteString(w, `{"data": []}`)
+			return
+		}
+		io.WriteString(w, `{"choices":[{"message":{"content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}))
+}
+
+func TestWarmupBuildsClients(t *testing.T) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gpt": {Provider: "openai", Model: "gpt-4o", APIKey: "k", BaseURL: srv.URL},
+	}}
+	r, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+	r.mu.Lock()
+	_, built := r.clients[clientCacheKey(cfg.Models["gpt"])]
+	r.mu.Unlock()
+	if !built {
+		t.Error("Warmup did not pre-build the provider client")
+	}
+
+	if err := r.Warmup(context.Background(), "missing"); err == nil {
+		t.Error("Warmup with unknown key should fail")
+	}
+}
+
+func TestWarmupTypes(t *testing.T) {
+	if err := WarmupTypes[itemList](); err != nil {
+		t.Errorf("WarmupTypes[itemList]: %v", err)
+	}
+	if err := WarmupTypes[func()](); err == nil {
+		t.Error("WarmupTypes on an unreflectable type should fail")
+	}
+}
+
+// BenchmarkFirstCall compares the first call on a cold router against one
+// that was warmed up, against a local fake server.
+func BenchmarkFirstCall(b *testing.B) {
+	srv := fakeOpenAIServer()
+	defer srv.Close()
+	cfg := &config.LLMConfig{Models: map[string]config.ModelConfig{
+		"gpt": {Provider: "openai", Model: "gpt-4o", APIKey: "k", BaseURL: srv.URL},
+	}}
+	req := Request{Model: "gpt", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+
+	for _, warmed := range []bool{false, true} {
+		name := "cold"
+		if warmed {
+			name = "warmed"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				r, err := NewRouter(cfg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if warmed {
+					if err := r.Warmup(context.Background()); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.StartTimer()
+				if _, err := r.ExecuteRaw(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}